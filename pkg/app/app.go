@@ -2,14 +2,16 @@ package app
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/Melodia-IS2/melodia-go-utils/pkg/router"
 )
 
 type App struct {
-	router *router.Router
-	port   string
+	router     *router.Router
+	port       string
+	routeTable io.Writer
 }
 
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -20,6 +22,11 @@ func (a *App) Run() error {
 	if a.port == "" {
 		a.port = "8080"
 	}
+	if a.routeTable != nil {
+		if err := a.router.PrintRoutes(a.routeTable); err != nil {
+			return err
+		}
+	}
 	fmt.Println("Server is running on port", a.port)
 	err := http.ListenAndServe(":"+a.port, a.router)
 	if err != nil {
@@ -2,19 +2,25 @@ package app
 
 import (
 	"context"
+	"io"
 	"net/http"
 
 	"github.com/Melodia-IS2/melodia-events/pkg/suscriber/kafka"
 	httpUtils "github.com/Melodia-IS2/melodia-go-utils/pkg/http"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/middleware"
 	"github.com/Melodia-IS2/melodia-go-utils/pkg/router"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/validation"
+
+	"github.com/go-playground/validator/v10"
 )
 
 type Builder struct {
-	app       App
-	port      string
-	router    *router.Router
-	workers   []Worker
-	consumers []kafka.Consumer
+	app        App
+	port       string
+	router     *router.Router
+	workers    []Worker
+	consumers  []kafka.Consumer
+	routeTable io.Writer
 }
 
 func NewBuilder(rtcfg *router.RouterConfig, port string) (*Builder, error) {
@@ -47,6 +53,33 @@ func (b *Builder) RegisterMiddleware(middleware func(http.Handler) http.Handler)
 	return b
 }
 
+func (b *Builder) WithCORS(cfg middleware.CORSConfig) *Builder {
+	return b.RegisterMiddleware(middleware.CORS(cfg))
+}
+
+func (b *Builder) WithSecurityHeaders(cfg middleware.SecurityHeadersConfig) *Builder {
+	return b.RegisterMiddleware(middleware.SecurityHeaders(cfg))
+}
+
+func (b *Builder) WithCompression(cfg middleware.CompressionConfig) *Builder {
+	return b.RegisterMiddleware(middleware.Compress(cfg))
+}
+
+// WithRouteTable prints the route table to w when the app starts, e.g. WithRouteTable(os.Stdout).
+func (b *Builder) WithRouteTable(w io.Writer) *Builder {
+	b.routeTable = w
+	return b
+}
+
+// RegisterValidator adds a validate tag for every request parsed by the service. msg may be nil.
+// It panics if the tag cannot be registered.
+func (b *Builder) RegisterValidator(tag string, fn validator.Func, msg validation.MessageFunc) *Builder {
+	if err := validation.Register(tag, fn, msg); err != nil {
+		panic(err)
+	}
+	return b
+}
+
 func (b *Builder) RegisterConsumer(consumer kafka.Consumer) *Builder {
 	b.consumers = append(b.consumers, consumer)
 	return b
@@ -60,7 +93,8 @@ func (b *Builder) Build() *App {
 		go consumer.Start(context.Background())
 	}
 	return &App{
-		router: b.router,
-		port:   b.port,
+		router:     b.router,
+		port:       b.port,
+		routeTable: b.routeTable,
 	}
 }
@@ -0,0 +1,215 @@
+// Package audio identifies audio files from their content and reads their properties and tags.
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+type Format string
+
+const (
+	MP3  Format = "mp3"
+	FLAC Format = "flac"
+	WAV  Format = "wav"
+	OGG  Format = "ogg"
+	M4A  Format = "m4a"
+)
+
+// AllFormats are the formats Inspect recognizes.
+var AllFormats = []Format{MP3, FLAC, WAV, OGG, M4A}
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported audio format")
+	ErrCorrupted         = errors.New("corrupted audio file")
+)
+
+// maxTagSize bounds the memory used to read tags, which may embed cover art.
+const maxTagSize = 16 << 20
+
+type Info struct {
+	Format      Format        `json:"format"`
+	ContentType string        `json:"content_type"`
+	Duration    time.Duration `json:"duration"`
+	// Bitrate is the average bitrate in bits per second.
+	Bitrate    int `json:"bitrate"`
+	SampleRate int `json:"sample_rate"`
+	Channels   int `json:"channels"`
+
+	Title  string `json:"title,omitempty"`
+	Artist string `json:"artist,omitempty"`
+	Album  string `json:"album,omitempty"`
+	ISRC   string `json:"isrc,omitempty"`
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case MP3:
+		return "audio/mpeg"
+	case FLAC:
+		return "audio/flac"
+	case WAV:
+		return "audio/wav"
+	case OGG:
+		return "audio/ogg"
+	case M4A:
+		return "audio/mp4"
+	}
+	return "application/octet-stream"
+}
+
+// Inspect identifies the format of the size bytes of r from their content and parses its properties
+// and tags. It fails with ErrUnsupportedFormat or ErrCorrupted.
+func Inspect(r io.ReaderAt, size int64) (Info, error) {
+	head, err := readAt(r, 0, min(size, 12))
+	if err != nil {
+		return Info{}, err
+	}
+
+	var info Info
+	switch {
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		info, err = inspectFLAC(r, size, 0, nil)
+	case bytes.HasPrefix(head, []byte("RIFF")) && len(head) >= 12 && string(head[8:12]) == "WAVE":
+		info, err = inspectWAV(r, size)
+	case bytes.HasPrefix(head, []byte("OggS")):
+		info, err = inspectOGG(r, size)
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		info, err = inspectM4A(r, size)
+	case bytes.HasPrefix(head, []byte("ID3")):
+		// FLAC files sometimes start with an ID3 tag too.
+		info, err = inspectID3Prefixed(r, size)
+	case len(head) >= 2 && isFrameSync(head):
+		info, err = inspectMP3(r, size, 0, nil)
+	default:
+		return Info{}, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	info.ContentType = info.Format.ContentType()
+	return info, nil
+}
+
+// InspectFileHeader inspects a form file and returns a validation AppError when it is not one of
+// the allowed formats or is corrupted. No allowed formats allows all of them.
+func InspectFileHeader(fileHeader *multipart.FileHeader, allowed ...Format) (Info, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return Info{}, err
+	}
+	defer file.Close()
+
+	info, err := Inspect(file, fileHeader.Size)
+	if errors.Is(err, ErrUnsupportedFormat) || (err == nil && len(allowed) > 0 && !slices.Contains(allowed, info.Format)) {
+		if len(allowed) == 0 {
+			allowed = AllFormats
+		}
+		names := make([]string, len(allowed))
+		for i, format := range allowed {
+			names[i] = strings.ToUpper(string(format))
+		}
+		return Info{}, pkgErrors.NewValidationError(fmt.Sprintf("File must be an audio file in one of the formats: %s", strings.Join(names, ", ")))
+	}
+	if errors.Is(err, ErrCorrupted) {
+		return Info{}, pkgErrors.NewValidationError("Audio file is corrupted")
+	}
+	return info, err
+}
+
+// Metadata returns the info as object user metadata. Values that are not ASCII, such as most
+// titles, are encoded as RFC 2047 words since metadata travels in HTTP headers; decode them with
+// mime.WordDecoder.
+func (i Info) Metadata() map[string]string {
+	metadata := map[string]string{
+		"format":      string(i.Format),
+		"duration_ms": strconv.FormatInt(i.Duration.Milliseconds(), 10),
+		"bitrate":     strconv.Itoa(i.Bitrate),
+		"sample_rate": strconv.Itoa(i.SampleRate),
+		"channels":    strconv.Itoa(i.Channels),
+	}
+	for key, value := range map[string]string{"title": i.Title, "artist": i.Artist, "album": i.Album, "isrc": i.ISRC} {
+		if value != "" {
+			metadata[key] = headerValue(value)
+		}
+	}
+	return metadata
+}
+
+func headerValue(value string) string {
+	for _, r := range value {
+		if r < ' ' || r > '~' {
+			return mime.QEncoding.Encode("utf-8", value)
+		}
+	}
+	return value
+}
+
+// readAt reads n bytes at offset, failing with ErrCorrupted when the file is shorter.
+func readAt(r io.ReaderAt, offset int64, n int64) ([]byte, error) {
+	if offset < 0 || n < 0 {
+		return nil, ErrCorrupted
+	}
+	buf := make([]byte, n)
+	read, err := r.ReadAt(buf, offset)
+	if int64(read) == n {
+		return buf, nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: unexpected end of file", ErrCorrupted)
+	}
+	return nil, err
+}
+
+// bitrate returns the average bitrate of size bytes lasting duration.
+func bitrate(size int64, duration time.Duration) int {
+	if duration <= 0 {
+		return 0
+	}
+	return int(float64(size) * 8 / duration.Seconds())
+}
+
+func samplesDuration(samples int64, sampleRate int) time.Duration {
+	if sampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(samples) / float64(sampleRate) * float64(time.Second))
+}
+
+// cleanTag trims the padding tags usually carry and drops invalid UTF-8.
+func cleanTag(value string) string {
+	value = strings.TrimRight(value, "\x00 ")
+	if !utf8.ValidString(value) {
+		value = strings.ToValidUTF8(value, "")
+	}
+	return strings.TrimSpace(value)
+}
+
+// tags collects the first value of each tag.
+type tags struct {
+	title, artist, album, isrc string
+}
+
+func (t *tags) set(field *string, value string) {
+	if *field == "" {
+		*field = cleanTag(value)
+	}
+}
+
+func (t tags) apply(info *Info) {
+	info.Title = t.title
+	info.Artist = t.artist
+	info.Album = t.album
+	info.ISRC = strings.ToUpper(strings.ReplaceAll(t.isrc, "-", ""))
+}
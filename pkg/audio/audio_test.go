@@ -0,0 +1,310 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"mime"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// mp3Frames returns count MPEG1 Layer III frames of 128kbps at 44.1kHz, 417 bytes each. first
+// replaces the start of the first frame's content, e.g. with a Xing header.
+func mp3Frames(count int, first []byte) []byte {
+	var data []byte
+	for i := range count {
+		frame := make([]byte, 417)
+		copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+		if i == 0 {
+			copy(frame[4:], first)
+		}
+		data = append(data, frame...)
+	}
+	return data
+}
+
+func xingHeader(frames, size uint32) []byte {
+	header := make([]byte, 32+16)
+	copy(header[32:], "Xing")
+	binary.BigEndian.PutUint32(header[36:], 0x03)
+	binary.BigEndian.PutUint32(header[40:], frames)
+	binary.BigEndian.PutUint32(header[44:], size)
+	return header
+}
+
+func id3v2(frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	size := len(body)
+	header := []byte{'I', 'D', '3', 3, 0, 0, byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}
+	return append(header, body...)
+}
+
+func id3Frame(id string, data []byte) []byte {
+	frame := make([]byte, 10, 10+len(data))
+	copy(frame, id)
+	binary.BigEndian.PutUint32(frame[4:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+func utf16Text(value string) []byte {
+	data := []byte{1, 0xFF, 0xFE}
+	for _, unit := range utf16.Encode([]rune(value)) {
+		data = binary.LittleEndian.AppendUint16(data, unit)
+	}
+	return data
+}
+
+func id3v1(title, artist, album string) []byte {
+	tag := make([]byte, id3v1Size)
+	copy(tag, "TAG")
+	copy(tag[3:33], title)
+	copy(tag[33:63], artist)
+	copy(tag[63:93], album)
+	return tag
+}
+
+func vorbisComment(comments ...string) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, 4)
+	data = append(data, "test"...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(comments)))
+	for _, comment := range comments {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(comment)))
+		data = append(data, comment...)
+	}
+	return data
+}
+
+func flacBlock(blockType byte, last bool, data []byte) []byte {
+	if last {
+		blockType |= 0x80
+	}
+	length := len(data)
+	return append([]byte{blockType, byte(length >> 16), byte(length >> 8), byte(length)}, data...)
+}
+
+// flacStream returns a FLAC stream info block of 16 bit stereo audio.
+func flacStream(sampleRate int, samples int64) []byte {
+	data := make([]byte, flacStreamInfoLen)
+	data[10] = byte(sampleRate >> 12)
+	data[11] = byte(sampleRate >> 4)
+	data[12] = byte(sampleRate&0x0F)<<4 | 1<<1
+	data[13] = 0xF0 | byte(samples>>32)
+	binary.BigEndian.PutUint32(data[14:], uint32(samples))
+	return data
+}
+
+func riffChunk(id string, data []byte) []byte {
+	chunk := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func wav(chunks ...[]byte) []byte {
+	body := append([]byte("WAVE"), bytes.Join(chunks, nil)...)
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+// wavFormat is a PCM format chunk of 16 bit stereo audio at 44.1kHz, 176400 bytes per second.
+func wavFormat() []byte {
+	data := binary.LittleEndian.AppendUint16(nil, 1)
+	data = binary.LittleEndian.AppendUint16(data, 2)
+	data = binary.LittleEndian.AppendUint32(data, 44100)
+	data = binary.LittleEndian.AppendUint32(data, 176400)
+	data = binary.LittleEndian.AppendUint16(data, 4)
+	data = binary.LittleEndian.AppendUint16(data, 16)
+	return riffChunk("fmt ", data)
+}
+
+// oggPageData returns an Ogg page holding packets, each shorter than 255 bytes.
+func oggPageData(serial uint32, granule int64, packets ...[]byte) []byte {
+	header := make([]byte, oggPageHeaderSize, oggPageHeaderSize+len(packets))
+	copy(header, "OggS")
+	binary.LittleEndian.PutUint64(header[6:], uint64(granule))
+	binary.LittleEndian.PutUint32(header[14:], serial)
+	header[26] = byte(len(packets))
+	for _, packet := range packets {
+		header = append(header, byte(len(packet)))
+	}
+	return append(header, bytes.Join(packets, nil)...)
+}
+
+func vorbisIdentification(channels byte, sampleRate uint32) []byte {
+	data := append([]byte("\x01vorbis"), 0, 0, 0, 0, channels)
+	data = binary.LittleEndian.AppendUint32(data, sampleRate)
+	return append(data, make([]byte, 14)...)
+}
+
+func opusHead(channels byte, preSkip uint16, sampleRate uint32) []byte {
+	data := append([]byte("OpusHead"), 1, channels)
+	data = binary.LittleEndian.AppendUint16(data, preSkip)
+	data = binary.LittleEndian.AppendUint32(data, sampleRate)
+	return append(data, 0, 0, 0)
+}
+
+func box(kind string, children ...[]byte) []byte {
+	body := bytes.Join(children, nil)
+	return append(append(binary.BigEndian.AppendUint32(nil, uint32(8+len(body))), kind...), body...)
+}
+
+// mvhd returns a version 0 mvhd or mdhd box content lasting seconds.
+func mvhd(seconds uint32) []byte {
+	data := make([]byte, 20)
+	binary.BigEndian.PutUint32(data[12:], 1000)
+	binary.BigEndian.PutUint32(data[16:], seconds*1000)
+	return data
+}
+
+func mp4Track(handler string, channels, sampleRate uint16) []byte {
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:], handler)
+	entry := make([]byte, 28)
+	binary.BigEndian.PutUint16(entry[16:], channels)
+	binary.BigEndian.PutUint16(entry[24:], sampleRate)
+	stsd := append(make([]byte, 8), box("mp4a", entry)...)
+	return box("trak", box("mdia",
+		box("hdlr", hdlr),
+		box("minf", box("stbl", box("stsd", stsd))),
+	))
+}
+
+func mp4Item(kind string, value string) []byte {
+	return box(kind, box("data", append(make([]byte, 8), value...)))
+}
+
+func m4a(moov ...[]byte) []byte {
+	ftyp := box("ftyp", []byte("M4A \x00\x00\x00\x00isom"))
+	return append(append(ftyp, box("mdat", make([]byte, 1000))...), box("moov", moov...)...)
+}
+
+func TestInspect(t *testing.T) {
+	cbr := mp3Frames(10, nil)
+	flacTags := flacBlock(flacVorbisComment, true, vorbisComment("TITLE=Song", "artist=Band", "ISRC=us-abc-12-34567"))
+	udta := box("udta", box("meta", make([]byte, 4), box("hdlr", make([]byte, 24)),
+		box("ilst", mp4Item("\xa9nam", "Song"), mp4Item("\xa9ART", "Band"))))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    Info
+		wantErr error
+	}{
+		{
+			name: "MP3 CBR",
+			data: cbr,
+			want: Info{Format: MP3, ContentType: "audio/mpeg", Duration: 260625 * time.Microsecond, Bitrate: 128000, SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "MP3 with ID3v2 and ID3v1",
+			data: append(append(id3v2(
+				id3Frame("TIT2", append([]byte{0}, "Song\x00"...)),
+				id3Frame("TPE1", utf16Text("Bänd")),
+				id3Frame("TSRC", append([]byte{3}, "US-ABC-12-34567"...)),
+			), cbr...), id3v1("Other", "Other", "Album")...),
+			want: Info{
+				Format: MP3, ContentType: "audio/mpeg", Duration: 260625 * time.Microsecond, Bitrate: 128000, SampleRate: 44100, Channels: 2,
+				Title: "Song", Artist: "Bänd", Album: "Album", ISRC: "USABC1234567",
+			},
+		},
+		{
+			name: "MP3 Xing",
+			data: mp3Frames(3, xingHeader(100, 1251)),
+			want: Info{Format: MP3, ContentType: "audio/mpeg", Duration: 2612244897, Bitrate: 3831, SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "FLAC",
+			data: append(append([]byte("fLaC"), flacBlock(flacStreamInfo, false, flacStream(44100, 441000))...), append(flacTags, make([]byte, 1000)...)...),
+			want: Info{
+				Format: FLAC, ContentType: "audio/flac", Duration: 10 * time.Second, Bitrate: 800, SampleRate: 44100, Channels: 2,
+				Title: "Song", Artist: "Band", ISRC: "USABC1234567",
+			},
+		},
+		{
+			name: "FLAC after ID3",
+			data: append(id3v2(id3Frame("TALB", append([]byte{0}, "Album"...))), append([]byte("fLaC"), append(flacBlock(flacStreamInfo, false, flacStream(48000, 96000)), flacTags...)...)...),
+			want: Info{
+				Format: FLAC, ContentType: "audio/flac", Duration: 2 * time.Second, SampleRate: 48000, Channels: 2,
+				Title: "Song", Artist: "Band", Album: "Album", ISRC: "USABC1234567",
+			},
+		},
+		{
+			name: "WAV",
+			data: wav(wavFormat(), riffChunk("LIST", append([]byte("INFO"), riffChunk("INAM", []byte("Song\x00"))...)), riffChunk("data", make([]byte, 17640))),
+			want: Info{Format: WAV, ContentType: "audio/wav", Duration: 100 * time.Millisecond, Bitrate: 1411200, SampleRate: 44100, Channels: 2, Title: "Song"},
+		},
+		{
+			name: "Ogg Vorbis",
+			data: append(
+				oggPageData(7, 0, vorbisIdentification(2, 44100), append([]byte("\x03vorbis"), vorbisComment("TITLE=Song")...)),
+				oggPageData(7, 3*44100, make([]byte, 100))...),
+			want: Info{Format: OGG, ContentType: "audio/ogg", Duration: 3 * time.Second, Bitrate: 586, SampleRate: 44100, Channels: 2, Title: "Song"},
+		},
+		{
+			name: "Ogg Opus",
+			data: append(append(
+				oggPageData(7, 0, opusHead(2, 312, 44100)),
+				oggPageData(7, 0, append([]byte("OpusTags"), vorbisComment("ARTIST=Band")...))...),
+				oggPageData(7, 2*48000+312, make([]byte, 100))...),
+			want: Info{Format: OGG, ContentType: "audio/ogg", Duration: 2 * time.Second, Bitrate: 952, SampleRate: 44100, Channels: 2, Artist: "Band"},
+		},
+		{
+			name: "M4A",
+			data: m4a(box("mvhd", mvhd(5)), mp4Track("soun", 2, 44100), udta),
+			want: Info{Format: M4A, ContentType: "audio/mp4", Duration: 5 * time.Second, Bitrate: 2073, SampleRate: 44100, Channels: 2, Title: "Song", Artist: "Band"},
+		},
+		{name: "M4A video", data: m4a(box("mvhd", mvhd(5)), mp4Track("vide", 0, 0)), wantErr: ErrUnsupportedFormat},
+		{name: "M4A without moov", data: box("ftyp", []byte("M4A \x00\x00\x00\x00")), wantErr: ErrCorrupted},
+		{name: "M4A bad box size", data: append(box("ftyp", []byte("M4A ")), 0, 0, 0, 4, 'm', 'o', 'o', 'v'), wantErr: ErrCorrupted},
+		{name: "MP3 without frames", data: append([]byte{0xFF, 0xFB}, make([]byte, 100)...), wantErr: ErrCorrupted},
+		{name: "ID3 longer than the file", data: id3v2(id3Frame("TIT2", make([]byte, 20)))[:20], wantErr: ErrCorrupted},
+		{name: "FLAC truncated", data: []byte("fLaC"), wantErr: ErrCorrupted},
+		{name: "FLAC block longer than the file", data: append([]byte("fLaC"), flacBlock(flacStreamInfo, true, flacStream(44100, 1))[:20]...), wantErr: ErrCorrupted},
+		{name: "FLAC without stream info", data: append([]byte("fLaC"), flacTags...), wantErr: ErrCorrupted},
+		{name: "WAV without data", data: wav(wavFormat()), wantErr: ErrCorrupted},
+		{name: "Ogg unknown codec", data: oggPageData(7, 0, []byte("\x80theora"), []byte("\x81theora")), wantErr: ErrUnsupportedFormat},
+		{name: "Ogg truncated page", data: oggPageData(7, 0, vorbisIdentification(2, 44100))[:40], wantErr: ErrCorrupted},
+		{name: "unknown", data: []byte("%PDF-1.7 not audio"), wantErr: ErrUnsupportedFormat},
+		{name: "empty", data: nil, wantErr: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Inspect(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Inspect =\n%+v, want\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInfoMetadata(t *testing.T) {
+	info := Info{Format: MP3, Duration: 1500 * time.Millisecond, Bitrate: 128000, SampleRate: 44100, Channels: 2, Title: "Canción", Artist: "Band"}
+	metadata := info.Metadata()
+
+	want := map[string]string{"format": "mp3", "duration_ms": "1500", "bitrate": "128000", "sample_rate": "44100", "channels": "2", "artist": "Band"}
+	for key, value := range want {
+		if metadata[key] != value {
+			t.Errorf("%s = %q, want %q", key, metadata[key], value)
+		}
+	}
+	if _, ok := metadata["album"]; ok {
+		t.Error("empty album in metadata")
+	}
+	title, err := new(mime.WordDecoder).DecodeHeader(metadata["title"])
+	if err != nil || title != "Canción" || metadata["title"] == "Canción" {
+		t.Errorf("title = %q, decoded %q, %v", metadata["title"], title, err)
+	}
+}
@@ -0,0 +1,130 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	flacStreamInfo    = 0
+	flacVorbisComment = 4
+	flacInvalidBlock  = 127
+	flacStreamInfoLen = 34
+)
+
+// inspectFLAC reads the FLAC stream starting at offset. Tags missing from its Vorbis comment
+// are taken from fallback, the ID3 tag some files start with.
+func inspectFLAC(r io.ReaderAt, size int64, offset int64, fallback *tags) (Info, error) {
+	info := Info{Format: FLAC}
+	var t tags
+	var totalSamples int64
+	hasStreamInfo := false
+
+	pos := offset + 4
+	for {
+		header, err := readAt(r, pos, 4)
+		if err != nil {
+			return Info{}, err
+		}
+		last := header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+		if pos+4+length > size {
+			return Info{}, fmt.Errorf("%w: FLAC metadata block is longer than the file", ErrCorrupted)
+		}
+
+		switch blockType {
+		case flacStreamInfo:
+			if length < flacStreamInfoLen {
+				return Info{}, fmt.Errorf("%w: invalid FLAC stream info", ErrCorrupted)
+			}
+			data, err := readAt(r, pos+4, flacStreamInfoLen)
+			if err != nil {
+				return Info{}, err
+			}
+			// 20 bits of sample rate, 3 of channels - 1, 5 of bits per sample - 1 and 36 of samples.
+			info.SampleRate = int(data[10])<<12 | int(data[11])<<4 | int(data[12])>>4
+			info.Channels = int(data[12]>>1&0x07) + 1
+			totalSamples = int64(data[13]&0x0F)<<32 | int64(binary.BigEndian.Uint32(data[14:18]))
+			hasStreamInfo = true
+		case flacVorbisComment:
+			if length <= maxTagSize {
+				data, err := readAt(r, pos+4, length)
+				if err != nil {
+					return Info{}, err
+				}
+				if !readVorbisComment(data, &t) {
+					return Info{}, fmt.Errorf("%w: invalid Vorbis comment", ErrCorrupted)
+				}
+			}
+		case flacInvalidBlock:
+			return Info{}, fmt.Errorf("%w: invalid FLAC metadata block", ErrCorrupted)
+		}
+
+		pos += 4 + length
+		if last {
+			break
+		}
+	}
+
+	if !hasStreamInfo || info.SampleRate == 0 {
+		return Info{}, fmt.Errorf("%w: missing FLAC stream info", ErrCorrupted)
+	}
+	info.Duration = samplesDuration(totalSamples, info.SampleRate)
+	info.Bitrate = bitrate(size-pos, info.Duration)
+
+	if fallback != nil {
+		t.set(&t.title, fallback.title)
+		t.set(&t.artist, fallback.artist)
+		t.set(&t.album, fallback.album)
+		t.set(&t.isrc, fallback.isrc)
+	}
+	t.apply(&info)
+	return info, nil
+}
+
+// readVorbisComment reads the tags of a Vorbis comment, as used by FLAC, Vorbis and Opus.
+func readVorbisComment(data []byte, t *tags) bool {
+	next := func() (string, bool) {
+		if len(data) < 4 {
+			return "", false
+		}
+		length := int64(binary.LittleEndian.Uint32(data))
+		if int64(len(data)-4) < length {
+			return "", false
+		}
+		value := string(data[4 : 4+length])
+		data = data[4+length:]
+		return value, true
+	}
+
+	if _, ok := next(); !ok {
+		// Vendor string.
+		return false
+	}
+	if len(data) < 4 {
+		return false
+	}
+	count := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	for range count {
+		comment, ok := next()
+		if !ok {
+			return false
+		}
+		key, value, _ := strings.Cut(comment, "=")
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			t.set(&t.title, value)
+		case "ARTIST":
+			t.set(&t.artist, value)
+		case "ALBUM":
+			t.set(&t.album, value)
+		case "ISRC":
+			t.set(&t.isrc, value)
+		}
+	}
+	return true
+}
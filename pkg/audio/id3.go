@@ -0,0 +1,201 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	id3HeaderSize = 10
+	id3v1Size     = 128
+)
+
+// readID3v2 reads the ID3v2 tag at offset into t and returns its size, or 0 when there is none.
+func readID3v2(r io.ReaderAt, size int64, offset int64, t *tags) (int64, error) {
+	if size-offset < id3HeaderSize {
+		return 0, nil
+	}
+	header, err := readAt(r, offset, id3HeaderSize)
+	if err != nil || string(header[:3]) != "ID3" {
+		return 0, err
+	}
+
+	major, flags := header[3], header[5]
+	tagSize, ok := syncsafe(header[6:10])
+	if !ok || major < 2 || major > 4 {
+		return 0, fmt.Errorf("%w: invalid ID3 header", ErrCorrupted)
+	}
+	total := id3HeaderSize + tagSize
+	if flags&0x10 != 0 {
+		// Footer.
+		total += id3HeaderSize
+	}
+	if offset+total > size {
+		return 0, fmt.Errorf("%w: ID3 tag is longer than the file", ErrCorrupted)
+	}
+
+	body, err := readAt(r, offset+id3HeaderSize, min(tagSize, maxTagSize))
+	if err != nil {
+		return 0, err
+	}
+	if flags&0x80 != 0 && major < 4 {
+		body = unsynchronize(body)
+	}
+
+	pos := 0
+	if flags&0x40 != 0 && len(body) >= 4 {
+		// Extended header, whose size excludes itself in v2.3.
+		if major == 3 {
+			pos = 4 + int(binary.BigEndian.Uint32(body))
+		} else if extended, ok := syncsafe(body[:4]); ok {
+			pos = int(extended)
+		}
+	}
+
+	headerSize := 10
+	if major == 2 {
+		headerSize = 6
+	}
+	for pos >= 0 && pos+headerSize <= len(body) && body[pos] != 0 {
+		var id string
+		var frameSize int
+		var frameFlags byte
+		switch major {
+		case 2:
+			id = string(body[pos : pos+3])
+			frameSize = int(body[pos+3])<<16 | int(body[pos+4])<<8 | int(body[pos+5])
+		case 3:
+			id = string(body[pos : pos+4])
+			frameSize = int(binary.BigEndian.Uint32(body[pos+4:]))
+			frameFlags = body[pos+9]
+		default:
+			id = string(body[pos : pos+4])
+			size, ok := syncsafe(body[pos+4 : pos+8])
+			if !ok {
+				return total, fmt.Errorf("%w: invalid ID3 frame size", ErrCorrupted)
+			}
+			frameSize = int(size)
+			frameFlags = body[pos+9]
+		}
+
+		start := pos + headerSize
+		end := start + frameSize
+		if frameSize < 0 || end > len(body) {
+			// Truncated by maxTagSize or by the writer, the frames read so far are kept.
+			break
+		}
+		pos = end
+
+		data := body[start:end]
+		if major == 3 && frameFlags&0xC0 != 0 || major == 4 && frameFlags&0x0C != 0 {
+			// Compressed or encrypted.
+			continue
+		}
+		if major == 4 {
+			if frameFlags&0x02 != 0 {
+				data = unsynchronize(data)
+			}
+			if frameFlags&0x01 != 0 {
+				if len(data) < 4 {
+					continue
+				}
+				data = data[4:]
+			}
+		}
+
+		switch id {
+		case "TIT2", "TT2":
+			t.set(&t.title, id3Text(data))
+		case "TPE1", "TP1":
+			t.set(&t.artist, id3Text(data))
+		case "TALB", "TAL":
+			t.set(&t.album, id3Text(data))
+		case "TSRC", "TRC":
+			t.set(&t.isrc, id3Text(data))
+		}
+	}
+	return total, nil
+}
+
+// readID3v1 reads the ID3v1 tag at the end of the file into t and reports whether there is one.
+func readID3v1(r io.ReaderAt, size int64, t *tags) (bool, error) {
+	if size < id3v1Size {
+		return false, nil
+	}
+	tag, err := readAt(r, size-id3v1Size, id3v1Size)
+	if err != nil || string(tag[:3]) != "TAG" {
+		return false, err
+	}
+	t.set(&t.title, latin1(tag[3:33]))
+	t.set(&t.artist, latin1(tag[33:63]))
+	t.set(&t.album, latin1(tag[63:93]))
+	return true, nil
+}
+
+func syncsafe(b []byte) (int64, bool) {
+	var value int64
+	for _, c := range b {
+		if c&0x80 != 0 {
+			return 0, false
+		}
+		value = value<<7 | int64(c)
+	}
+	return value, true
+}
+
+// unsynchronize removes the zero bytes inserted after 0xFF bytes.
+func unsynchronize(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xFF, 0x00}, []byte{0xFF})
+}
+
+// id3Text decodes the first value of a text frame.
+func id3Text(data []byte) string {
+	if len(data) < 1 {
+		return ""
+	}
+	encoding, text := data[0], data[1:]
+
+	var value string
+	switch encoding {
+	case 0:
+		value = latin1(text)
+	case 1, 2:
+		value = decodeUTF16(text, encoding == 2)
+	default:
+		value = string(text)
+	}
+	first, _, _ := strings.Cut(value, "\x00")
+	return first
+}
+
+func decodeUTF16(data []byte, bigEndian bool) string {
+	if len(data) >= 2 {
+		switch {
+		case data[0] == 0xFE && data[1] == 0xFF:
+			bigEndian, data = true, data[2:]
+		case data[0] == 0xFF && data[1] == 0xFE:
+			bigEndian, data = false, data[2:]
+		}
+	}
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, binary.BigEndian.Uint16(data[i:]))
+		} else {
+			units = append(units, binary.LittleEndian.Uint16(data[i:]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+func latin1(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
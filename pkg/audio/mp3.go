@@ -0,0 +1,216 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// mp3SyncWindow is how far after the tags the first frame is searched for.
+const mp3SyncWindow = 64 << 10
+
+const (
+	mpeg25 = 0
+	mpeg2  = 2
+	mpeg1  = 3
+)
+
+var mp3Bitrates = map[[2]int][16]int{
+	{mpeg1, 3}: {0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+	{mpeg1, 2}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+	{mpeg1, 1}: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	{mpeg2, 3}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+	{mpeg2, 2}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	{mpeg2, 1}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+}
+
+var mp3SampleRates = map[int][3]int{
+	mpeg1:  {44100, 48000, 32000},
+	mpeg2:  {22050, 24000, 16000},
+	mpeg25: {11025, 12000, 8000},
+}
+
+type mp3Frame struct {
+	version    int
+	layer      int
+	bitrate    int
+	sampleRate int
+	channels   int
+	size       int
+	samples    int
+}
+
+func isFrameSync(b []byte) bool {
+	return b[0] == 0xFF && b[1]&0xE0 == 0xE0
+}
+
+// parseFrameHeader parses the 4 byte header of an MPEG audio frame.
+func parseFrameHeader(b []byte) (mp3Frame, bool) {
+	if len(b) < 4 || !isFrameSync(b) {
+		return mp3Frame{}, false
+	}
+	version := int(b[1]>>3) & 0x03
+	layer := int(b[1]>>1) & 0x03
+	bitrateIndex := int(b[2] >> 4)
+	sampleRateIndex := int(b[2]>>2) & 0x03
+	padding := int(b[2]>>1) & 0x01
+	if version == 1 || layer == 0 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return mp3Frame{}, false
+	}
+
+	table := version
+	if version == mpeg25 {
+		table = mpeg2
+	}
+	frame := mp3Frame{
+		version:    version,
+		layer:      layer,
+		bitrate:    mp3Bitrates[[2]int{table, layer}][bitrateIndex] * 1000,
+		sampleRate: mp3SampleRates[version][sampleRateIndex],
+		channels:   2,
+	}
+	if b[3]>>6 == 3 {
+		frame.channels = 1
+	}
+
+	switch {
+	case layer == 3:
+		// Layer I.
+		frame.samples = 384
+		frame.size = (12*frame.bitrate/frame.sampleRate + padding) * 4
+	case layer == 2 || version == mpeg1:
+		frame.samples = 1152
+		frame.size = 144*frame.bitrate/frame.sampleRate + padding
+	default:
+		frame.samples = 576
+		frame.size = 72*frame.bitrate/frame.sampleRate + padding
+	}
+	return frame, frame.size > 4
+}
+
+func inspectID3Prefixed(r io.ReaderAt, size int64) (Info, error) {
+	var t tags
+	tagSize, err := readID3v2(r, size, 0, &t)
+	if err != nil {
+		return Info{}, err
+	}
+
+	if size-tagSize >= 4 {
+		magic, err := readAt(r, tagSize, 4)
+		if err != nil {
+			return Info{}, err
+		}
+		if string(magic) == "fLaC" {
+			return inspectFLAC(r, size, tagSize, &t)
+		}
+	}
+	return inspectMP3(r, size, tagSize, &t)
+}
+
+func inspectMP3(r io.ReaderAt, size int64, start int64, t *tags) (Info, error) {
+	if t == nil {
+		t = &tags{}
+	}
+	hasID3v1, err := readID3v1(r, size, t)
+	if err != nil {
+		return Info{}, err
+	}
+	end := size
+	if hasID3v1 {
+		end -= id3v1Size
+	}
+
+	window, err := readAt(r, start, max(0, min(end-start, mp3SyncWindow)))
+	if err != nil {
+		return Info{}, err
+	}
+	offset, frame, ok := findFrame(window, end-start)
+	if !ok {
+		return Info{}, fmt.Errorf("%w: no MPEG audio frame found", ErrCorrupted)
+	}
+	frameStart := start + int64(offset)
+
+	info := Info{
+		Format:     MP3,
+		SampleRate: frame.sampleRate,
+		Channels:   frame.channels,
+		Bitrate:    frame.bitrate,
+	}
+	audioSize := end - frameStart
+
+	if frames, bytes, ok := vbrHeader(window[offset:], frame); ok {
+		info.Duration = samplesDuration(frames*int64(frame.samples), frame.sampleRate)
+		if bytes > 0 && bytes <= audioSize {
+			audioSize = bytes
+		}
+		info.Bitrate = bitrate(audioSize, info.Duration)
+	} else {
+		info.Duration = time.Duration(float64(audioSize) * 8 / float64(frame.bitrate) * float64(time.Second))
+	}
+
+	t.apply(&info)
+	return info, nil
+}
+
+// findFrame returns the first frame of window followed by another valid frame, so that sync
+// patterns inside the tags are not mistaken for audio. remaining is the audio size after window's start.
+func findFrame(window []byte, remaining int64) (int, mp3Frame, bool) {
+	for i := 0; i+4 <= len(window); i++ {
+		frame, ok := parseFrameHeader(window[i:])
+		if !ok {
+			continue
+		}
+		next := i + frame.size
+		if int64(next)+4 > remaining {
+			// A file with a single frame.
+			return i, frame, int64(next) <= remaining
+		}
+		if next+4 > len(window) {
+			return i, frame, true
+		}
+		if following, ok := parseFrameHeader(window[next:]); ok && following.version == frame.version && following.layer == frame.layer {
+			return i, frame, true
+		}
+	}
+	return 0, mp3Frame{}, false
+}
+
+// vbrHeader reads the frame and byte counts of the Xing, Info or VBRI header of the first frame.
+func vbrHeader(frameData []byte, frame mp3Frame) (int64, int64, bool) {
+	sideInfo := 32
+	switch {
+	case frame.version == mpeg1 && frame.channels == 1:
+		sideInfo = 17
+	case frame.version != mpeg1 && frame.channels == 2:
+		sideInfo = 17
+	case frame.version != mpeg1:
+		sideInfo = 9
+	}
+
+	xing := 4 + sideInfo
+	if len(frameData) >= xing+16 {
+		tag := string(frameData[xing : xing+4])
+		if tag == "Xing" || tag == "Info" {
+			flags := binary.BigEndian.Uint32(frameData[xing+4:])
+			pos := xing + 8
+			var frames, bytes int64
+			if flags&0x01 != 0 {
+				frames = int64(binary.BigEndian.Uint32(frameData[pos:]))
+				pos += 4
+			}
+			if flags&0x02 != 0 && len(frameData) >= pos+4 {
+				bytes = int64(binary.BigEndian.Uint32(frameData[pos:]))
+			}
+			return frames, bytes, frames > 0
+		}
+	}
+
+	const vbri = 4 + 32
+	if len(frameData) >= vbri+18 && string(frameData[vbri:vbri+4]) == "VBRI" {
+		bytes := int64(binary.BigEndian.Uint32(frameData[vbri+10:]))
+		frames := int64(binary.BigEndian.Uint32(frameData[vbri+14:]))
+		return frames, bytes, frames > 0
+	}
+	return 0, 0, false
+}
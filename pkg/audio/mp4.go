@@ -0,0 +1,236 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxMoovSize bounds the memory used to read the metadata of an MP4 file.
+const maxMoovSize = 64 << 20
+
+type mp4Box struct {
+	kind string
+	data []byte
+}
+
+// findTopBox returns the position and size of the content of the first top level box of kind, without
+// reading the boxes before it.
+func findTopBox(r io.ReaderAt, size int64, kind string) (int64, int64, error) {
+	for pos := int64(0); pos+8 <= size; {
+		header, err := readAt(r, pos, 8)
+		if err != nil {
+			return 0, 0, err
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = size - pos
+		case 1:
+			large, err := readAt(r, pos+8, 8)
+			if err != nil {
+				return 0, 0, err
+			}
+			boxSize = int64(binary.BigEndian.Uint64(large))
+			headerSize = 16
+		}
+		if boxSize < headerSize || pos+boxSize > size {
+			return 0, 0, fmt.Errorf("%w: invalid MP4 box", ErrCorrupted)
+		}
+		if string(header[4:8]) == kind {
+			return pos + headerSize, boxSize - headerSize, nil
+		}
+		pos += boxSize
+	}
+	return 0, 0, fmt.Errorf("%w: missing MP4 %s box", ErrCorrupted, kind)
+}
+
+// mp4Boxes splits the content of a box into its children.
+func mp4Boxes(data []byte) ([]mp4Box, bool) {
+	var boxes []mp4Box
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		headerSize := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, false
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			headerSize = 16
+		}
+		if size < headerSize || size > uint64(len(data)) {
+			return nil, false
+		}
+		boxes = append(boxes, mp4Box{kind: string(data[4:8]), data: data[headerSize:size]})
+		data = data[size:]
+	}
+	return boxes, true
+}
+
+func mp4Child(data []byte, kind string) ([]byte, bool) {
+	boxes, _ := mp4Boxes(data)
+	for _, box := range boxes {
+		if box.kind == kind {
+			return box.data, true
+		}
+	}
+	return nil, false
+}
+
+// mp4Path returns the content of the box at path under data, e.g. "mdia", "minf", "stbl".
+func mp4Path(data []byte, path ...string) ([]byte, bool) {
+	for _, kind := range path {
+		var ok bool
+		if data, ok = mp4Child(data, kind); !ok {
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+func inspectM4A(r io.ReaderAt, size int64) (Info, error) {
+	moovPos, moovSize, err := findTopBox(r, size, "moov")
+	if err != nil {
+		return Info{}, err
+	}
+	if moovSize > maxMoovSize {
+		return Info{}, fmt.Errorf("%w: MP4 metadata is too large", ErrCorrupted)
+	}
+	moov, err := readAt(r, moovPos, moovSize)
+	if err != nil {
+		return Info{}, err
+	}
+	boxes, ok := mp4Boxes(moov)
+	if !ok {
+		return Info{}, fmt.Errorf("%w: invalid MP4 metadata", ErrCorrupted)
+	}
+
+	info := Info{Format: M4A}
+	var t tags
+	hasAudio := false
+	for _, box := range boxes {
+		switch box.kind {
+		case "mvhd":
+			if duration, ok := mp4Duration(box.data); ok {
+				info.Duration = duration
+			}
+		case "trak":
+			handler, _ := mp4Path(box.data, "mdia", "hdlr")
+			if len(handler) < 12 {
+				continue
+			}
+			switch string(handler[8:12]) {
+			case "vide":
+				return Info{}, ErrUnsupportedFormat
+			case "soun":
+				if hasAudio {
+					continue
+				}
+				if !readAudioSampleEntry(box.data, &info) {
+					return Info{}, fmt.Errorf("%w: invalid MP4 audio track", ErrCorrupted)
+				}
+				hasAudio = true
+				if info.Duration == 0 {
+					if mdhd, ok := mp4Path(box.data, "mdia", "mdhd"); ok {
+						info.Duration, _ = mp4Duration(mdhd)
+					}
+				}
+			}
+		case "udta":
+			if meta, ok := mp4Child(box.data, "meta"); ok {
+				readILST(meta, &t)
+			}
+		case "meta":
+			readILST(box.data, &t)
+		}
+	}
+	if !hasAudio {
+		return Info{}, ErrUnsupportedFormat
+	}
+
+	info.Bitrate = bitrate(size, info.Duration)
+	t.apply(&info)
+	return info, nil
+}
+
+// mp4Duration reads the duration of an mvhd or mdhd box.
+func mp4Duration(data []byte) (time.Duration, bool) {
+	var timescale, duration uint64
+	switch {
+	case len(data) >= 32 && data[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(data[20:]))
+		duration = binary.BigEndian.Uint64(data[24:])
+	case len(data) >= 20 && data[0] == 0:
+		timescale = uint64(binary.BigEndian.Uint32(data[12:]))
+		duration = uint64(binary.BigEndian.Uint32(data[16:]))
+	default:
+		return 0, false
+	}
+	if timescale == 0 {
+		return 0, false
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), true
+}
+
+// readAudioSampleEntry reads the channels and sample rate of the first sample description of a track.
+func readAudioSampleEntry(trak []byte, info *Info) bool {
+	stsd, ok := mp4Path(trak, "mdia", "minf", "stbl", "stsd")
+	if !ok || len(stsd) < 8 {
+		return false
+	}
+	entries, ok := mp4Boxes(stsd[8:])
+	if !ok || len(entries) == 0 {
+		return false
+	}
+	// 6 reserved bytes, data reference index, version, revision, vendor, channels, sample size,
+	// compression id, packet size and a 16.16 sample rate.
+	entry := entries[0].data
+	if len(entry) < 28 {
+		return false
+	}
+	info.Channels = int(binary.BigEndian.Uint16(entry[16:]))
+	info.SampleRate = int(binary.BigEndian.Uint16(entry[24:]))
+	return info.Channels > 0
+}
+
+// readILST reads the iTunes tags of a meta box.
+func readILST(meta []byte, t *tags) {
+	// meta is a full box in MP4 files but QuickTime writes it without version and flags.
+	if len(meta) >= 8 && string(meta[4:8]) != "hdlr" {
+		meta = meta[4:]
+	}
+	ilst, ok := mp4Child(meta, "ilst")
+	if !ok {
+		return
+	}
+	items, _ := mp4Boxes(ilst)
+	for _, item := range items {
+		switch item.kind {
+		case "\xa9nam":
+			t.set(&t.title, mp4Text(item.data))
+		case "\xa9ART":
+			t.set(&t.artist, mp4Text(item.data))
+		case "\xa9alb":
+			t.set(&t.album, mp4Text(item.data))
+		case "----":
+			// Freeform item, named by its mean and name boxes.
+			if name, ok := mp4Child(item.data, "name"); ok && len(name) > 4 && string(name[4:]) == "ISRC" {
+				t.set(&t.isrc, mp4Text(item.data))
+			}
+		}
+	}
+}
+
+// mp4Text returns the value of the data box of an item: 4 bytes of type, 4 of locale and the value.
+func mp4Text(item []byte) string {
+	data, ok := mp4Child(item, "data")
+	if !ok || len(data) < 8 {
+		return ""
+	}
+	return string(data[8:])
+}
@@ -0,0 +1,173 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	oggPageHeaderSize = 27
+	// oggTailSize is how much of the end of the file is searched for the last page.
+	oggTailSize = 64 << 10
+	// Opus granule positions always count 48kHz samples.
+	opusGranuleRate = 48000
+)
+
+type oggPage struct {
+	serial   uint32
+	granule  int64
+	segments []byte
+	body     int64
+	next     int64
+}
+
+func readOggPage(r io.ReaderAt, size int64, pos int64) (oggPage, error) {
+	header, err := readAt(r, pos, oggPageHeaderSize)
+	if err != nil {
+		return oggPage{}, err
+	}
+	if string(header[:4]) != "OggS" || header[4] != 0 {
+		return oggPage{}, fmt.Errorf("%w: invalid Ogg page", ErrCorrupted)
+	}
+	segments, err := readAt(r, pos+oggPageHeaderSize, int64(header[26]))
+	if err != nil {
+		return oggPage{}, err
+	}
+
+	page := oggPage{
+		granule:  int64(binary.LittleEndian.Uint64(header[6:])),
+		serial:   binary.LittleEndian.Uint32(header[14:]),
+		segments: segments,
+		body:     pos + oggPageHeaderSize + int64(len(segments)),
+	}
+	page.next = page.body
+	for _, lacing := range segments {
+		page.next += int64(lacing)
+	}
+	if page.next > size {
+		return oggPage{}, fmt.Errorf("%w: Ogg page is longer than the file", ErrCorrupted)
+	}
+	return page, nil
+}
+
+// oggHeaders returns the first two packets of the first logical stream: the identification
+// and comment headers.
+func oggHeaders(r io.ReaderAt, size int64) ([][]byte, uint32, error) {
+	var packets [][]byte
+	var current []byte
+	var serial uint32
+
+	for pos := int64(0); len(packets) < 2; {
+		if pos >= size {
+			return nil, 0, fmt.Errorf("%w: missing Ogg headers", ErrCorrupted)
+		}
+		page, err := readOggPage(r, size, pos)
+		if err != nil {
+			return nil, 0, err
+		}
+		if pos == 0 {
+			serial = page.serial
+		}
+		pos = page.next
+		if page.serial != serial {
+			continue
+		}
+
+		body, err := readAt(r, page.body, page.next-page.body)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, lacing := range page.segments {
+			current = append(current, body[:lacing]...)
+			body = body[lacing:]
+			if len(current) > maxTagSize {
+				return nil, 0, fmt.Errorf("%w: Ogg header is too large", ErrCorrupted)
+			}
+			if lacing < 255 {
+				packets = append(packets, current)
+				current = nil
+				if len(packets) == 2 {
+					break
+				}
+			}
+		}
+	}
+	return packets, serial, nil
+}
+
+func inspectOGG(r io.ReaderAt, size int64) (Info, error) {
+	packets, serial, err := oggHeaders(r, size)
+	if err != nil {
+		return Info{}, err
+	}
+	identification, comment := packets[0], packets[1]
+
+	info := Info{Format: OGG}
+	var t tags
+	granuleRate := 0
+	var preSkip int64
+
+	switch {
+	case bytes.HasPrefix(identification, []byte("\x01vorbis")):
+		if len(identification) < 16 || !bytes.HasPrefix(comment, []byte("\x03vorbis")) {
+			return Info{}, fmt.Errorf("%w: invalid Vorbis headers", ErrCorrupted)
+		}
+		info.Channels = int(identification[11])
+		info.SampleRate = int(binary.LittleEndian.Uint32(identification[12:]))
+		granuleRate = info.SampleRate
+		comment = comment[7:]
+	case bytes.HasPrefix(identification, []byte("OpusHead")):
+		if len(identification) < 16 || !bytes.HasPrefix(comment, []byte("OpusTags")) {
+			return Info{}, fmt.Errorf("%w: invalid Opus headers", ErrCorrupted)
+		}
+		info.Channels = int(identification[9])
+		preSkip = int64(binary.LittleEndian.Uint16(identification[10:]))
+		// The rate of the original input, Opus always decodes at 48kHz.
+		info.SampleRate = int(binary.LittleEndian.Uint32(identification[12:]))
+		if info.SampleRate == 0 {
+			info.SampleRate = opusGranuleRate
+		}
+		granuleRate = opusGranuleRate
+		comment = comment[8:]
+	default:
+		return Info{}, ErrUnsupportedFormat
+	}
+	if info.Channels == 0 || granuleRate == 0 {
+		return Info{}, fmt.Errorf("%w: invalid Ogg identification header", ErrCorrupted)
+	}
+	if !readVorbisComment(comment, &t) {
+		return Info{}, fmt.Errorf("%w: invalid Vorbis comment", ErrCorrupted)
+	}
+
+	granule, err := lastGranule(r, size, serial)
+	if err != nil {
+		return Info{}, err
+	}
+	info.Duration = samplesDuration(max(0, granule-preSkip), granuleRate)
+	info.Bitrate = bitrate(size, info.Duration)
+	t.apply(&info)
+	return info, nil
+}
+
+// lastGranule returns the granule position of the last page of the stream, its length in samples.
+func lastGranule(r io.ReaderAt, size int64, serial uint32) (int64, error) {
+	start := max(0, size-oggTailSize)
+	tail, err := readAt(r, start, size-start)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		if len(tail)-i < oggPageHeaderSize {
+			continue
+		}
+		header := tail[i:]
+		granule := int64(binary.LittleEndian.Uint64(header[6:]))
+		if binary.LittleEndian.Uint32(header[14:]) == serial && granule >= 0 {
+			return granule, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: missing last Ogg page", ErrCorrupted)
+}
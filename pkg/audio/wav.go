@@ -0,0 +1,93 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+const riffHeaderSize = 12
+
+func inspectWAV(r io.ReaderAt, size int64) (Info, error) {
+	info := Info{Format: WAV}
+	var t tags
+	var byteRate int64
+	dataSize := int64(-1)
+
+	pos := int64(riffHeaderSize)
+	for pos+8 <= size {
+		header, err := readAt(r, pos, 8)
+		if err != nil {
+			return Info{}, err
+		}
+		id := string(header[:4])
+		length := int64(binary.LittleEndian.Uint32(header[4:]))
+		body := pos + 8
+		available := min(length, size-body)
+
+		switch id {
+		case "fmt ":
+			if length < 16 || available < 16 {
+				return Info{}, fmt.Errorf("%w: invalid WAV format chunk", ErrCorrupted)
+			}
+			data, err := readAt(r, body, 16)
+			if err != nil {
+				return Info{}, err
+			}
+			info.Channels = int(binary.LittleEndian.Uint16(data[2:]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(data[4:]))
+			byteRate = int64(binary.LittleEndian.Uint32(data[8:]))
+		case "data":
+			// Streamed files may declare a size larger than the file.
+			dataSize = available
+		case "LIST":
+			if available >= 4 && available <= maxTagSize {
+				data, err := readAt(r, body, available)
+				if err != nil {
+					return Info{}, err
+				}
+				if string(data[:4]) == "INFO" {
+					readRIFFInfo(data[4:], &t)
+				}
+			}
+		case "id3 ", "ID3 ":
+			if _, err := readID3v2(r, body+available, body, &t); err != nil {
+				return Info{}, err
+			}
+		}
+		pos = body + length + length%2
+	}
+
+	if byteRate == 0 || info.Channels == 0 || info.SampleRate == 0 || dataSize < 0 {
+		return Info{}, fmt.Errorf("%w: missing WAV format or data chunk", ErrCorrupted)
+	}
+	info.Duration = time.Duration(float64(dataSize) / float64(byteRate) * float64(time.Second))
+	info.Bitrate = int(byteRate * 8)
+	t.apply(&info)
+	return info, nil
+}
+
+// readRIFFInfo reads the tags of a LIST INFO chunk. Its ISRC field is the source, not a recording code.
+func readRIFFInfo(data []byte, t *tags) {
+	for len(data) >= 8 {
+		id := string(data[:4])
+		length := int(binary.LittleEndian.Uint32(data[4:]))
+		if length > len(data)-8 {
+			return
+		}
+		value := string(data[8 : 8+length])
+		switch id {
+		case "INAM":
+			t.set(&t.title, value)
+		case "IART":
+			t.set(&t.artist, value)
+		case "IPRD":
+			t.set(&t.album, value)
+		}
+		data = data[8+length:]
+		if length%2 == 1 && len(data) > 0 {
+			data = data[1:]
+		}
+	}
+}
@@ -23,6 +23,20 @@ type BuilderInterface interface {
 	Build(next func(w http.ResponseWriter, r *http.Request) error) func(w http.ResponseWriter, r *http.Request) error
 }
 
+// RequirementsProvider is implemented by builders that can describe their checks. It is separate from
+// BuilderInterface so existing implementations and mocks keep compiling.
+type RequirementsProvider interface {
+	Requirements() Requirements
+}
+
+// Requirements describes the checks configured in a Builder, used for documentation and route listings.
+type Requirements struct {
+	States []ctx.ContextState
+	Roles  []ctx.ContextRol
+	Claims []string
+	Custom int
+}
+
 type AuthMiddleware struct {
 	JWTSecretKey string
 }
@@ -77,8 +91,9 @@ func (a *AuthMiddleware) CheckKeyValue(c context.Context, key string, validValue
 type MiddlewareValidation func(r *http.Request) error
 
 type Builder struct {
-	auth   *AuthMiddleware
-	checks []MiddlewareValidation
+	auth         *AuthMiddleware
+	checks       []MiddlewareValidation
+	requirements Requirements
 }
 
 func (a *AuthMiddleware) NewBuilder() BuilderInterface {
@@ -86,6 +101,7 @@ func (a *AuthMiddleware) NewBuilder() BuilderInterface {
 }
 
 func (b *Builder) WithState(allowed ...ctx.ContextState) BuilderInterface {
+	b.requirements.States = append(b.requirements.States, allowed...)
 	upper := make([]string, len(allowed))
 	for i, v := range allowed {
 		upper[i] = strings.ToUpper(string(v))
@@ -101,6 +117,7 @@ func (b *Builder) WithState(allowed ...ctx.ContextState) BuilderInterface {
 }
 
 func (b *Builder) WithRol(allowed ...ctx.ContextRol) BuilderInterface {
+	b.requirements.Roles = append(b.requirements.Roles, allowed...)
 	upper := make([]string, len(allowed))
 	for i, v := range allowed {
 		upper[i] = strings.ToUpper(string(v))
@@ -116,6 +133,7 @@ func (b *Builder) WithRol(allowed ...ctx.ContextRol) BuilderInterface {
 }
 
 func (b *Builder) WithClaim(claimKey string, predicate func(value any) bool, errMsg string) BuilderInterface {
+	b.requirements.Claims = append(b.requirements.Claims, claimKey)
 	b.checks = append(b.checks, func(r *http.Request) error {
 		value := r.Context().Value(claimKey)
 		if !predicate(value) {
@@ -127,6 +145,7 @@ func (b *Builder) WithClaim(claimKey string, predicate func(value any) bool, err
 }
 
 func (b *Builder) WithCustom(fn func(r *http.Request) error) BuilderInterface {
+	b.requirements.Custom++
 	b.checks = append(b.checks, fn)
 	return b
 }
@@ -141,3 +160,7 @@ func (b *Builder) Build(next func(w http.ResponseWriter, r *http.Request) error)
 		return next(w, r)
 	})
 }
+
+func (b *Builder) Requirements() Requirements {
+	return b.requirements
+}
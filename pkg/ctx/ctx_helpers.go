@@ -3,6 +3,8 @@ package ctx
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/Melodia-IS2/melodia-go-utils/pkg/region"
 	"github.com/google/uuid"
@@ -26,6 +28,40 @@ const (
 	ContextRolGuest  ContextRol = "guest"
 )
 
+func (s ContextState) IsValid() bool {
+	switch s {
+	case ContextStateActive, ContextStateBlocked, ContextStateMissingInfo, ContextStateNoSession:
+		return true
+	}
+	return false
+}
+
+func (s *ContextState) UnmarshalText(text []byte) error {
+	value := ContextState(strings.ToLower(strings.TrimSpace(string(text))))
+	if value != "" && !value.IsValid() {
+		return fmt.Errorf("invalid state: %s", text)
+	}
+	*s = value
+	return nil
+}
+
+func (r ContextRol) IsValid() bool {
+	switch r {
+	case ContextRolAdmin, ContextRolArtist, ContextRolUser, ContextRolGuest:
+		return true
+	}
+	return false
+}
+
+func (r *ContextRol) UnmarshalText(text []byte) error {
+	value := ContextRol(strings.ToLower(strings.TrimSpace(string(text))))
+	if value != "" && !value.IsValid() {
+		return fmt.Errorf("invalid rol: %s", text)
+	}
+	*r = value
+	return nil
+}
+
 func GetUserID(ctx context.Context) (uuid.UUID, error) {
 	userIDStr, ok := ctx.Value("userID").(string)
 	if !ok {
@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 type AppError struct {
@@ -10,6 +11,14 @@ type AppError struct {
 	Title    string
 	Message  string
 	HTTPCode int
+	Fields   []FieldError
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
 }
 
 func (e *AppError) Error() string {
@@ -42,6 +51,16 @@ func NewValidationError(msg string) *AppError {
 	}
 }
 
+func NewFieldValidationError(fields []FieldError) *AppError {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	appError := NewValidationError(strings.Join(messages, ", "))
+	appError.Fields = fields
+	return appError
+}
+
 func NewConflictError(resource string, identifier any) *AppError {
 	if identifier == nil {
 		return &AppError{
@@ -103,3 +122,30 @@ func NewUnprocessableEntityError(msg string) *AppError {
 		HTTPCode: http.StatusUnprocessableEntity,
 	}
 }
+
+func NewTooManyRequestsError(msg string) *AppError {
+	return &AppError{
+		Code:     "TOO_MANY_REQUESTS",
+		Title:    "Too Many Requests",
+		Message:  msg,
+		HTTPCode: http.StatusTooManyRequests,
+	}
+}
+
+func NewPayloadTooLargeError(msg string) *AppError {
+	return &AppError{
+		Code:     "PAYLOAD_TOO_LARGE",
+		Title:    "Payload Too Large",
+		Message:  msg,
+		HTTPCode: http.StatusRequestEntityTooLarge,
+	}
+}
+
+func NewUnsupportedMediaTypeError(msg string) *AppError {
+	return &AppError{
+		Code:     "UNSUPPORTED_MEDIA_TYPE",
+		Title:    "Unsupported Media Type",
+		Message:  msg,
+		HTTPCode: http.StatusUnsupportedMediaType,
+	}
+}
@@ -96,6 +96,7 @@ type HTTPClientConfig struct {
 	ResetTimeout  time.Duration
 	RetryAttempts int
 	RetryDelay    time.Duration
+	Transport     http.RoundTripper
 }
 
 func NewRobustHTTPClient(config HTTPClientConfig) *RobustHTTPClient {
@@ -105,14 +106,19 @@ func NewRobustHTTPClient(config HTTPClientConfig) *RobustHTTPClient {
 		RequestTimeout: config.Timeout,
 	}
 
+	transport := config.Transport
+	if transport == nil {
+		transport = &http.Transport{
+			MaxIdleConns:       10,
+			IdleConnTimeout:    30 * time.Second,
+			DisableCompression: true,
+		}
+	}
+
 	return &RobustHTTPClient{
 		client: &http.Client{
-			Timeout: config.Timeout,
-			Transport: &http.Transport{
-				MaxIdleConns:       10,
-				IdleConnTimeout:    30 * time.Second,
-				DisableCompression: true,
-			},
+			Timeout:   config.Timeout,
+			Transport: transport,
 		},
 		circuitBreaker: NewCircuitBreaker(cbConfig),
 		retryAttempts:  config.RetryAttempts,
@@ -2,17 +2,11 @@ package http
 
 import (
 	"errors"
-	"fmt"
-	"mime/multipart"
 	"net/http"
-	"reflect"
-	"strconv"
-	"strings"
 
 	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
 	"github.com/Melodia-IS2/melodia-go-utils/pkg/router"
-
-	"github.com/go-playground/validator"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/validation"
 )
 
 var exposeErrorDetail = false
@@ -30,6 +24,7 @@ func ErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
 			Status:   appError.HTTPCode,
 			Detail:   appError.Message,
 			Instance: r.URL.Path,
+			Errors:   appError.Fields,
 		})
 	} else {
 		detail := "An unexpected error occurred"
@@ -66,95 +61,29 @@ func MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func ParseBody[T any](r *http.Request) (T, error) {
-	request, err := router.MapRequest[T](r)
-	if err != nil {
-		return request, pkgErrors.NewBadRequestError("Invalid request body")
+// ParseBody decodes the JSON body into T and validates it. Options such as router.Strict
+// tighten decoding; every decode failure is returned as an AppError.
+func ParseBody[T any](r *http.Request, opts ...router.DecodeOption) (T, error) {
+	var request T
+	if err := router.DecodeJSON(r, &request, opts...); err != nil {
+		return request, err
 	}
 
-	if err := validator.New().Struct(request); err != nil {
-		return request, pkgErrors.NewValidationError(formatValidationError(err))
+	if err := validation.Struct(request); err != nil {
+		return request, err
 	}
 	return request, nil
 }
 
+// Deprecated: use router.BindForm, which also limits files and validates.
 func MapRequestForm[T any](r *http.Request, maxMemoryMB int64) (*T, error) {
 	if err := r.ParseMultipartForm(maxMemoryMB << 20); err != nil {
 		return nil, err
 	}
 
-	result := new(T)
-	val := reflect.ValueOf(result).Elem()
-	typ := val.Type()
-
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		tag := field.Tag.Get("form")
-		if tag == "" {
-			continue
-		}
-
-		fv := val.Field(i)
-
-		switch fv.Kind() {
-		case reflect.String:
-			fv.SetString(r.FormValue(tag))
-		case reflect.Bool:
-			v := r.FormValue(tag)
-			fv.SetBool(v == "true" || v == "1")
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			v := r.FormValue(tag)
-			if v != "" {
-				num, err := strconv.ParseInt(v, 10, 64)
-				if err != nil {
-					return nil, err
-				}
-				fv.SetInt(num)
-			}
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			v := r.FormValue(tag)
-			if v != "" {
-				num, err := strconv.ParseUint(v, 10, 64)
-				if err != nil {
-					return nil, err
-				}
-				fv.SetUint(num)
-			}
-		case reflect.Ptr:
-			if fv.Type() == reflect.TypeOf(&multipart.FileHeader{}) {
-				file, header, err := r.FormFile(tag)
-				if err == nil {
-					fv.Set(reflect.ValueOf(header))
-					file.Close()
-				}
-			} else {
-				return nil, errors.New("unsupported pointer type: " + fv.Type().String())
-			}
-		default:
-			return nil, errors.New("unsupported field type: " + fv.Type().String())
-		}
-	}
-
-	return result, nil
-}
-
-func formatValidationError(err error) string {
-	if errs, ok := err.(validator.ValidationErrors); ok {
-		messages := make([]string, 0, len(errs))
-		for _, e := range errs {
-			field := e.Field()
-			switch e.Tag() {
-			case "required":
-				messages = append(messages, fmt.Sprintf("%s is required", field))
-			case "min":
-				messages = append(messages, fmt.Sprintf("%s must be at least %s characters", field, e.Param()))
-			case "max":
-				messages = append(messages, fmt.Sprintf("%s must be less than %s characters", field, e.Param()))
-			default:
-				messages = append(messages, fmt.Sprintf("%s is invalid", field))
-			}
-		}
-		return strings.Join(messages, ", ")
+	result, err := router.MapFormRequest[T](r)
+	if err != nil {
+		return nil, err
 	}
-	return err.Error()
+	return &result, nil
 }
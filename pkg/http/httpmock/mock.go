@@ -0,0 +1,258 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Expectation struct {
+	method  string
+	path    string
+	body    []byte
+	matcher func(r *http.Request, body []byte) bool
+
+	status  int
+	header  http.Header
+	resBody []byte
+	err     error
+	delay   time.Duration
+
+	times int
+	calls int
+}
+
+func (e *Expectation) WithBody(body string) *Expectation {
+	e.body = []byte(body)
+	return e
+}
+
+func (e *Expectation) WithJSONBody(body any) *Expectation {
+	data, err := json.Marshal(body)
+	if err != nil {
+		panic(fmt.Sprintf("httpmock: invalid json body: %v", err))
+	}
+	e.body = data
+	return e
+}
+
+func (e *Expectation) WithMatcher(matcher func(r *http.Request, body []byte) bool) *Expectation {
+	e.matcher = matcher
+	return e
+}
+
+func (e *Expectation) Return(status int, body string) *Expectation {
+	e.status = status
+	e.resBody = []byte(body)
+	return e
+}
+
+func (e *Expectation) ReturnJSON(status int, body any) *Expectation {
+	data, err := json.Marshal(body)
+	if err != nil {
+		panic(fmt.Sprintf("httpmock: invalid json body: %v", err))
+	}
+	e.status = status
+	e.resBody = data
+	e.header.Set("Content-Type", "application/json")
+	return e
+}
+
+func (e *Expectation) ReturnHeader(key, value string) *Expectation {
+	e.header.Add(key, value)
+	return e
+}
+
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) Delay(d time.Duration) *Expectation {
+	e.delay = d
+	return e
+}
+
+// Times limits how many requests the expectation answers. Zero means unlimited.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+func (e *Expectation) matches(r *http.Request, body []byte) bool {
+	if e.times > 0 && e.calls >= e.times {
+		return false
+	}
+	if !strings.EqualFold(e.method, r.Method) {
+		return false
+	}
+	if e.path != r.URL.Path {
+		return false
+	}
+	if e.body != nil && !bodyEqual(e.body, body) {
+		return false
+	}
+	if e.matcher != nil && !e.matcher(r, body) {
+		return false
+	}
+	return true
+}
+
+func (e *Expectation) String() string {
+	return fmt.Sprintf("%s %s", e.method, e.path)
+}
+
+type MockTransport struct {
+	mutex        sync.Mutex
+	expectations []*Expectation
+	requests     []*http.Request
+}
+
+func NewMockTransport() *MockTransport {
+	return &MockTransport{}
+}
+
+func (m *MockTransport) On(method, path string) *Expectation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	e := &Expectation{
+		method: method,
+		path:   path,
+		status: http.StatusOK,
+		header: http.Header{},
+	}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	m.requests = append(m.requests, withBody(req, body))
+	var match *Expectation
+	for _, e := range m.expectations {
+		if e.matches(req, body) {
+			match = e
+			e.calls++
+			break
+		}
+	}
+	m.mutex.Unlock()
+
+	if match == nil {
+		return nil, fmt.Errorf("httpmock: no expectation matches %s %s", req.Method, req.URL.Path)
+	}
+
+	if match.delay > 0 {
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(match.delay):
+		}
+	}
+
+	if match.err != nil {
+		return nil, match.err
+	}
+
+	return newResponse(req, match.status, match.header, match.resBody), nil
+}
+
+func (m *MockTransport) Requests() []*http.Request {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]*http.Request(nil), m.requests...)
+}
+
+// Pending returns the expectations with a Times limit that were not fully consumed.
+func (m *MockTransport) Pending() []*Expectation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var pending []*Expectation
+	for _, e := range m.expectations {
+		if e.times > 0 && e.calls < e.times {
+			pending = append(pending, e)
+		}
+	}
+	return pending
+}
+
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+func (m *MockTransport) AssertExpectations(t TestingT) {
+	t.Helper()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, e := range m.expectations {
+		if e.times > 0 && e.calls < e.times {
+			t.Errorf("httpmock: expectation %s was called %d of %d times", e, e.calls, e.times)
+		}
+	}
+}
+
+// readBody reads and closes the request body. A RoundTripper must not modify the request, so the
+// body is not replaced; use withBody to pass the request on.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// withBody returns a copy of req whose body can be read again.
+func withBody(req *http.Request, body []byte) *http.Request {
+	clone := req.Clone(req.Context())
+	if body == nil {
+		return clone
+	}
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return clone
+}
+
+func bodyEqual(expected, actual []byte) bool {
+	var a, b any
+	if json.Unmarshal(expected, &a) == nil && json.Unmarshal(actual, &b) == nil {
+		ea, _ := json.Marshal(a)
+		eb, _ := json.Marshal(b)
+		return bytes.Equal(ea, eb)
+	}
+	return bytes.Equal(bytes.TrimSpace(expected), bytes.TrimSpace(actual))
+}
+
+func newResponse(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,93 @@
+package httpmock
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMockTransportDoesNotModifyRequest(t *testing.T) {
+	mock := NewMockTransport()
+	mock.On(http.MethodPost, "/songs").WithJSONBody(map[string]string{"title": "a"}).Return(http.StatusCreated, "{}")
+
+	body := io.NopCloser(strings.NewReader(`{"title": "a"}`))
+	req, _ := http.NewRequest(http.MethodPost, "http://api/songs", body)
+
+	resp, err := mock.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if req.Body != body {
+		t.Fatal("RoundTrip replaced the request body")
+	}
+
+	recorded, err := io.ReadAll(mock.Requests()[0].Body)
+	if err != nil || string(recorded) != `{"title": "a"}` {
+		t.Fatalf("recorded body = %q, %v", recorded, err)
+	}
+}
+
+func TestMockTransportAssertExpectations(t *testing.T) {
+	mock := NewMockTransport()
+	mock.On(http.MethodGet, "/songs").Times(2)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://api/songs", nil)
+	if _, err := mock.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &testingRecorder{}
+	mock.AssertExpectations(recorder)
+	if len(recorder.errors) != 1 {
+		t.Fatalf("errors = %v", recorder.errors)
+	}
+}
+
+func TestRecordReplayBinaryBody(t *testing.T) {
+	payload := []byte{0x00, 0xff, 0xfe, 0x80, 'O', 'g', 'g'}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	golden := filepath.Join(t.TempDir(), "golden.json")
+	roundTrip := func(mode RecorderMode) []byte {
+		transport, err := NewRecordReplayTransport(RecorderConfig{GoldenFile: golden, Mode: mode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest(http.MethodPut, server.URL+"/upload", bytes.NewReader(payload))
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return body
+	}
+
+	if got := roundTrip(ModeRecord); !bytes.Equal(got, payload) {
+		t.Fatalf("recorded response = %v", got)
+	}
+	if got := roundTrip(ModeReplay); !bytes.Equal(got, payload) {
+		t.Fatalf("replayed response = %v", got)
+	}
+}
+
+type testingRecorder struct {
+	errors []string
+}
+
+func (r *testingRecorder) Helper() {}
+
+func (r *testingRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, format)
+}
@@ -0,0 +1,210 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type RecorderMode int
+
+const (
+	// ModeAuto replays when the golden file exists and records otherwise.
+	ModeAuto RecorderMode = iota
+	ModeRecord
+	ModeReplay
+)
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	// Body is base64 encoded in the golden file, so binary payloads are kept as is.
+	Body []byte `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecorderConfig struct {
+	GoldenFile string
+	Mode       RecorderMode
+	// Transport is used to reach the real server while recording. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// RedactHeaders are replaced by "REDACTED" before being written to the golden file.
+	RedactHeaders []string
+}
+
+type RecordReplayTransport struct {
+	config       RecorderConfig
+	mode         RecorderMode
+	mutex        sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+func NewRecordReplayTransport(config RecorderConfig) (*RecordReplayTransport, error) {
+	if config.GoldenFile == "" {
+		return nil, errors.New("golden file is empty")
+	}
+	if config.Transport == nil {
+		config.Transport = http.DefaultTransport
+	}
+	if len(config.RedactHeaders) == 0 {
+		config.RedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+	}
+
+	t := &RecordReplayTransport{config: config, mode: config.Mode}
+
+	if t.mode == ModeAuto {
+		if _, err := os.Stat(config.GoldenFile); err == nil {
+			t.mode = ModeReplay
+		} else {
+			t.mode = ModeRecord
+		}
+	}
+
+	if t.mode == ModeReplay {
+		data, err := os.ReadFile(config.GoldenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read golden file: %w", err)
+		}
+		if err := json.Unmarshal(data, &t.interactions); err != nil {
+			return nil, fmt.Errorf("failed to decode golden file: %w", err)
+		}
+		t.used = make([]bool, len(t.interactions))
+	}
+
+	return t, nil
+}
+
+func (t *RecordReplayTransport) Mode() RecorderMode {
+	return t.mode
+}
+
+func (t *RecordReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mode == ModeReplay {
+		return t.replay(req)
+	}
+	return t.record(req)
+}
+
+func (t *RecordReplayTransport) replay(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i, interaction := range t.interactions {
+		if t.used[i] || !interactionMatches(interaction.Request, req, body) {
+			continue
+		}
+		t.used[i] = true
+		header := interaction.Response.Header
+		if header == nil {
+			header = http.Header{}
+		}
+		return newResponse(req, interaction.Response.Status, header, interaction.Response.Body), nil
+	}
+
+	return nil, fmt.Errorf("httpmock: no recorded interaction matches %s %s", req.Method, req.URL.Path)
+}
+
+func (t *RecordReplayTransport) record(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.config.Transport.RoundTrip(withBody(req, body))
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: t.redact(req.Header),
+			Body:   body,
+		},
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: t.redact(resp.Header),
+			Body:   resBody,
+		},
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.interactions = append(t.interactions, interaction)
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (t *RecordReplayTransport) save() error {
+	if err := os.MkdirAll(filepath.Dir(t.config.GoldenFile), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(t.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.config.GoldenFile, data, 0644)
+}
+
+func (t *RecordReplayTransport) redact(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	clone := header.Clone()
+	for _, key := range t.config.RedactHeaders {
+		if clone.Get(key) != "" {
+			clone.Set(key, "REDACTED")
+		}
+	}
+	return clone
+}
+
+func interactionMatches(recorded RecordedRequest, req *http.Request, body []byte) bool {
+	if !strings.EqualFold(recorded.Method, req.Method) {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil || recordedURL.Path != req.URL.Path {
+		return false
+	}
+	if len(recorded.Body) == 0 && len(body) == 0 {
+		return true
+	}
+	return bodyEqual(recorded.Body, body)
+}
@@ -0,0 +1,197 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/ctx"
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+type Config struct {
+	Store Store
+	TTL   time.Duration
+	// LockTimeout is how long a key stays locked by a request that neither completes nor fails, e.g.
+	// when the process crashes. Later requests with the key get 409 until then. It must be longer than
+	// the slowest request, or a retry may run while the first execution is still going. Defaults to 5 minutes.
+	LockTimeout time.Duration
+	// Required rejects unsafe requests without an Idempotency-Key header.
+	Required bool
+	// MaxKeyLength defaults to 255.
+	MaxKeyLength int
+	// MaxBodySize limits the body buffered to fingerprint the request. Bigger bodies return 413.
+	// Defaults to 10MB.
+	MaxBodySize int64
+	// AllowAnonymous lets requests without a user in the context use a key shared by every anonymous
+	// client. Without it those requests are rejected, so mounting the middleware before auth cannot
+	// replay the response of one client to another.
+	AllowAnonymous bool
+}
+
+type Middleware struct {
+	config Config
+}
+
+func NewMiddleware(config Config) *Middleware {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = 5 * time.Minute
+	}
+	if config.MaxKeyLength <= 0 {
+		config.MaxKeyLength = 255
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 10 << 20
+	}
+	return &Middleware{config: config}
+}
+
+// Handler wraps a handler the same way auth.Builder.Build does, so it must be placed inside the auth
+// wrapper for the key to be scoped to the user. Only successful executions are stored: when the handler
+// returns an error the key is released and the client may retry.
+func (m *Middleware) Handler(next func(w http.ResponseWriter, r *http.Request) error) func(w http.ResponseWriter, r *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if isSafeMethod(r.Method) {
+			return next(w, r)
+		}
+
+		key := r.Header.Get(HeaderIdempotencyKey)
+		if key == "" {
+			if m.config.Required {
+				return pkgErrors.NewBadRequestError("Idempotency-Key header is required")
+			}
+			return next(w, r)
+		}
+		if len(key) > m.config.MaxKeyLength {
+			return pkgErrors.NewBadRequestError("Idempotency-Key header is too long")
+		}
+
+		scopedKey, ok := scope(r, key)
+		if !ok && !m.config.AllowAnonymous {
+			return pkgErrors.NewUnauthorizedError("Idempotency-Key requires an authenticated user")
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, m.config.MaxBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return pkgErrors.NewPayloadTooLargeError(fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit))
+			}
+			return pkgErrors.NewBadRequestError("Invalid request body")
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := fingerprint(r, body)
+
+		record, started, err := m.config.Store.Begin(r.Context(), scopedKey, fingerprint, m.config.LockTimeout, m.config.TTL)
+		if err != nil {
+			return err
+		}
+
+		if !started {
+			if record.Fingerprint != fingerprint {
+				return pkgErrors.NewUnprocessableEntityError("Idempotency-Key was already used with a different request")
+			}
+			if record.Status != RecordStatusCompleted || record.Response == nil {
+				return &pkgErrors.AppError{
+					Code:     "IDEMPOTENCY_IN_PROGRESS",
+					Title:    "Request In Progress",
+					Message:  "A request with the same Idempotency-Key is still being processed",
+					HTTPCode: http.StatusConflict,
+				}
+			}
+			replay(w, record.Response)
+			return nil
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		if err := m.run(next, recorder, r, scopedKey); err != nil {
+			_ = m.config.Store.Release(r.Context(), scopedKey)
+			return err
+		}
+
+		return m.config.Store.Complete(r.Context(), scopedKey, Response{
+			StatusCode: recorder.status,
+			Header:     recorder.Header().Clone(),
+			Body:       recorder.body.Bytes(),
+		})
+	}
+}
+
+func (m *Middleware) run(next func(w http.ResponseWriter, r *http.Request) error, w http.ResponseWriter, r *http.Request, scopedKey string) error {
+	defer func() {
+		if rec := recover(); rec != nil {
+			_ = m.config.Store.Release(r.Context(), scopedKey)
+			panic(rec)
+		}
+	}()
+	return next(w, r)
+}
+
+// scope prefixes key with the user of the request. It returns false for requests without a user.
+func scope(r *http.Request, key string) (string, bool) {
+	if userID, err := ctx.GetUserID(r.Context()); err == nil {
+		return "user:" + userID.String() + ":" + key, true
+	}
+	return "anonymous:" + key, false
+}
+
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(r.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, response *Response) {
+	for key, values := range response.Header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(response.StatusCode)
+	_, _ = w.Write(response.Body)
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions || method == http.MethodTrace
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.wroteHeader {
+		return
+	}
+	rr.wroteHeader = true
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if !rr.wroteHeader {
+		rr.WriteHeader(http.StatusOK)
+	}
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,197 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+const testUserID = "5f0c3a0e-8f5e-4d8a-9d44-2d9f3b1c7a10"
+
+func newRequest(method, body, key string, authenticated bool) *http.Request {
+	r := httptest.NewRequest(method, "/songs", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(HeaderIdempotencyKey, key)
+	}
+	if authenticated {
+		r = r.WithContext(context.WithValue(r.Context(), "userID", testUserID))
+	}
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		requests []*http.Request
+		// statuses are the HTTP codes of each request, from the response or the returned AppError.
+		statuses []int
+		calls    int
+	}{
+		{
+			name:     "replays completed request",
+			requests: []*http.Request{newRequest(http.MethodPost, `{"a":1}`, "k1", true), newRequest(http.MethodPost, `{"a":1}`, "k1", true)},
+			statuses: []int{http.StatusCreated, http.StatusCreated},
+			calls:    1,
+		},
+		{
+			name:     "rejects key reused with another body",
+			requests: []*http.Request{newRequest(http.MethodPost, `{"a":1}`, "k1", true), newRequest(http.MethodPost, `{"a":2}`, "k1", true)},
+			statuses: []int{http.StatusCreated, http.StatusUnprocessableEntity},
+			calls:    1,
+		},
+		{
+			name:     "runs requests without key",
+			requests: []*http.Request{newRequest(http.MethodPost, `{}`, "", true), newRequest(http.MethodPost, `{}`, "", true)},
+			statuses: []int{http.StatusCreated, http.StatusCreated},
+			calls:    2,
+		},
+		{
+			name:     "requires key",
+			config:   Config{Required: true},
+			requests: []*http.Request{newRequest(http.MethodPost, `{}`, "", true)},
+			statuses: []int{http.StatusBadRequest},
+		},
+		{
+			name:     "rejects anonymous requests",
+			requests: []*http.Request{newRequest(http.MethodPost, `{}`, "k1", false)},
+			statuses: []int{http.StatusUnauthorized},
+		},
+		{
+			name:     "allows anonymous requests when configured",
+			config:   Config{AllowAnonymous: true},
+			requests: []*http.Request{newRequest(http.MethodPost, `{}`, "k1", false), newRequest(http.MethodPost, `{}`, "k1", false)},
+			statuses: []int{http.StatusCreated, http.StatusCreated},
+			calls:    1,
+		},
+		{
+			name:     "limits body size",
+			config:   Config{MaxBodySize: 4},
+			requests: []*http.Request{newRequest(http.MethodPost, `{"a":1}`, "k1", true)},
+			statuses: []int{http.StatusRequestEntityTooLarge},
+		},
+		{
+			name:     "rejects long keys",
+			config:   Config{MaxKeyLength: 3},
+			requests: []*http.Request{newRequest(http.MethodPost, `{}`, "long", true)},
+			statuses: []int{http.StatusBadRequest},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			handler := NewMiddleware(test.config).Handler(func(w http.ResponseWriter, r *http.Request) error {
+				calls++
+				if _, err := io.ReadAll(r.Body); err != nil {
+					return err
+				}
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte(`{"id":1}`))
+				return err
+			})
+
+			for i, r := range test.requests {
+				w := httptest.NewRecorder()
+				var status int
+				if err := handler(w, r); err != nil {
+					var appErr *pkgErrors.AppError
+					if !errors.As(err, &appErr) {
+						t.Fatalf("request %d: %v", i, err)
+					}
+					status = appErr.HTTPCode
+				} else {
+					status = w.Code
+				}
+				if status != test.statuses[i] {
+					t.Fatalf("request %d: status = %d, want %d", i, status, test.statuses[i])
+				}
+			}
+			if calls != test.calls {
+				t.Fatalf("calls = %d, want %d", calls, test.calls)
+			}
+		})
+	}
+}
+
+func TestMiddlewareReplaysResponse(t *testing.T) {
+	handler := NewMiddleware(Config{}).Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Location", "/songs/1")
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte(`{"id":1}`))
+		return err
+	})
+
+	for range 2 {
+		if err := handler(httptest.NewRecorder(), newRequest(http.MethodPost, `{}`, "k1", true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := httptest.NewRecorder()
+	if err := handler(w, newRequest(http.MethodPost, `{}`, "k1", true)); err != nil {
+		t.Fatal(err)
+	}
+	if w.Body.String() != `{"id":1}` || w.Header().Get("Location") != "/songs/1" || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("replayed %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+}
+
+func TestMiddlewareReleasesKeyOnError(t *testing.T) {
+	fail := true
+	handler := NewMiddleware(Config{}).Handler(func(w http.ResponseWriter, r *http.Request) error {
+		if fail {
+			return errors.New("failed")
+		}
+		w.WriteHeader(http.StatusCreated)
+		return nil
+	})
+
+	if err := handler(httptest.NewRecorder(), newRequest(http.MethodPost, `{}`, "k1", true)); err == nil {
+		t.Fatal("expected the handler error")
+	}
+	fail = false
+	w := httptest.NewRecorder()
+	if err := handler(w, newRequest(http.MethodPost, `{}`, "k1", true)); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("retry: %d %v", w.Code, err)
+	}
+}
+
+func TestMiddlewareTakesExpiredLocks(t *testing.T) {
+	store := NewMemoryStore()
+	handler := NewMiddleware(Config{Store: store}).Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusCreated)
+		return nil
+	})
+
+	// Records left in progress by crashed requests.
+	ctx := context.Background()
+	crashed := fingerprint(newRequest(http.MethodPost, `{}`, "", true), []byte(`{}`))
+	if _, _, err := store.Begin(ctx, "user:"+testUserID+":locked", crashed, time.Hour, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Begin(ctx, "user:"+testUserID+":expired", crashed, -time.Second, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	err := handler(httptest.NewRecorder(), newRequest(http.MethodPost, `{}`, "locked", true))
+	var appErr *pkgErrors.AppError
+	if !errors.As(err, &appErr) || appErr.HTTPCode != http.StatusConflict {
+		t.Fatalf("locked key: %v, want 409", err)
+	}
+
+	w := httptest.NewRecorder()
+	if err := handler(w, newRequest(http.MethodPost, `{}`, "expired", true)); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("expired lock: %d %v", w.Code, err)
+	}
+	w = httptest.NewRecorder()
+	if err := handler(w, newRequest(http.MethodPost, `{}`, "expired", true)); err != nil || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("completed after takeover: %d %v", w.Code, err)
+	}
+}
@@ -0,0 +1,147 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/database"
+)
+
+// MySQLSchema creates the table used by MySQLStore. %s is replaced with the table name.
+// idempotency_key is the SHA-256 of the key scoped to the user, so keys of any length fit.
+const MySQLSchema = `CREATE TABLE IF NOT EXISTS %s (
+	idempotency_key CHAR(64) NOT NULL PRIMARY KEY,
+	fingerprint CHAR(64) NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	status_code INT NULL,
+	headers JSON NULL,
+	body LONGBLOB NULL,
+	locked_until DATETIME(6) NOT NULL,
+	expires_at DATETIME(6) NOT NULL
+)`
+
+// MySQLStore needs a connection opened with parseTime=true.
+type MySQLStore struct {
+	db    *sql.DB
+	table string
+}
+
+func NewMySQLStore(db *sql.DB, table string) (*MySQLStore, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if table == "" {
+		table = "idempotency_keys"
+	}
+	return &MySQLStore{db: db, table: table}, nil
+}
+
+func (s *MySQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(MySQLSchema, s.table))
+	return err
+}
+
+// beginAttempts bounds the retries of Begin when the record it conflicted with is released before it is read.
+const beginAttempts = 3
+
+func (s *MySQLStore) Begin(ctx context.Context, key string, fingerprint string, lockTimeout time.Duration, ttl time.Duration) (*Record, bool, error) {
+	for attempt := 1; ; attempt++ {
+		record, started, err := s.begin(ctx, key, fingerprint, lockTimeout, ttl)
+		if errors.Is(err, sql.ErrNoRows) && attempt < beginAttempts {
+			continue
+		}
+		return record, started, err
+	}
+}
+
+// begin returns sql.ErrNoRows when the conflicting record was released or expired before it was read.
+func (s *MySQLStore) begin(ctx context.Context, key string, fingerprint string, lockTimeout time.Duration, ttl time.Duration) (*Record, bool, error) {
+	now := time.Now().UTC()
+	hashed := hashKey(key)
+
+	_, err := s.db.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE idempotency_key = ? AND (expires_at < ? OR (status = ? AND locked_until < ?))", s.table),
+		hashed, now, RecordStatusInProgress, now,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (idempotency_key, fingerprint, status, locked_until, expires_at) VALUES (?, ?, ?, ?, ?)", s.table),
+		hashed, fingerprint, RecordStatusInProgress, now.Add(lockTimeout), now.Add(ttl),
+	)
+	if err == nil {
+		return nil, true, nil
+	}
+	if sqlErr := database.HandleSqlError(err); sqlErr.ErrorType != database.SqlErrorTypeConflict {
+		return nil, false, err
+	}
+
+	record, err := s.get(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	return record, false, nil
+}
+
+func (s *MySQLStore) get(ctx context.Context, key string) (*Record, error) {
+	var (
+		record     Record
+		statusCode sql.NullInt64
+		headers    []byte
+		body       []byte
+	)
+
+	row := s.db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT idempotency_key, fingerprint, status, status_code, headers, body, locked_until, expires_at FROM %s WHERE idempotency_key = ?", s.table),
+		hashKey(key),
+	)
+	if err := row.Scan(&record.Key, &record.Fingerprint, &record.Status, &statusCode, &headers, &body, &record.LockedUntil, &record.ExpiresAt); err != nil {
+		return nil, err
+	}
+	record.Key = key
+
+	if record.Status == RecordStatusCompleted {
+		record.Response = &Response{StatusCode: int(statusCode.Int64), Body: body}
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &record.Response.Header); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &record, nil
+}
+
+func (s *MySQLStore) Complete(ctx context.Context, key string, response Response) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		fmt.Sprintf("UPDATE %s SET status = ?, status_code = ?, headers = ?, body = ? WHERE idempotency_key = ?", s.table),
+		RecordStatusCompleted, response.StatusCode, headers, response.Body, hashKey(key),
+	)
+	return err
+}
+
+func (s *MySQLStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE idempotency_key = ? AND status = ?", s.table),
+		hashKey(key), RecordStatusInProgress,
+	)
+	return err
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type RecordStatus string
+
+const (
+	RecordStatusInProgress RecordStatus = "in_progress"
+	RecordStatusCompleted  RecordStatus = "completed"
+)
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+type Record struct {
+	Key         string
+	Fingerprint string
+	Status      RecordStatus
+	Response    *Response
+	// LockedUntil is when an in progress record left by a crashed request can be taken again.
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
+
+type Store interface {
+	// Begin stores a new in progress record locked for lockTimeout. When the key already exists it returns
+	// the stored record and false, unless the record is in progress and its lock expired, which Begin
+	// replaces as if the key did not exist.
+	Begin(ctx context.Context, key string, fingerprint string, lockTimeout time.Duration, ttl time.Duration) (*Record, bool, error)
+	Complete(ctx context.Context, key string, response Response) error
+	// Release removes an in progress record so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+type MemoryStore struct {
+	mutex       sync.Mutex
+	records     map[string]*Record
+	lastCleanup time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]*Record{}}
+}
+
+func (s *MemoryStore) Begin(ctx context.Context, key string, fingerprint string, lockTimeout time.Duration, ttl time.Duration) (*Record, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.cleanup(now)
+
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) &&
+		(record.Status != RecordStatusInProgress || now.Before(record.LockedUntil)) {
+		copied := *record
+		return &copied, false, nil
+	}
+
+	s.records[key] = &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      RecordStatusInProgress,
+		LockedUntil: now.Add(lockTimeout),
+		ExpiresAt:   now.Add(ttl),
+	}
+	return nil, true, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, key string, response Response) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if record, ok := s.records[key]; ok {
+		record.Status = RecordStatusCompleted
+		record.Response = &response
+	}
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if record, ok := s.records[key]; ok && record.Status == RecordStatusInProgress {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < time.Minute {
+		return
+	}
+	s.lastCleanup = now
+
+	for key, record := range s.records {
+		if now.After(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	backgroundMutex   sync.Mutex
+	backgroundFlusher Flusher = &JSONFlusher{writer: os.Stderr}
+	backgroundAppName string
+)
+
+// SetBackgroundFlusher sets where Record writes the entries logged outside a request, e.g. at startup
+// or by workers. Defaults to JSON lines on stderr.
+func SetBackgroundFlusher(flusher Flusher, appName string) {
+	backgroundMutex.Lock()
+	defer backgroundMutex.Unlock()
+	backgroundFlusher = flusher
+	backgroundAppName = appName
+}
+
+// Record adds an entry to the request log of ctx like Add. Outside a request, where Add would drop it,
+// the entry is flushed right away as a log of its own.
+func Record(ctx context.Context, level Level, layer Layer, msg string, data any) {
+	if FromContext(ctx) != nil {
+		Add(ctx, level, layer, msg, data)
+		return
+	}
+
+	backgroundMutex.Lock()
+	defer backgroundMutex.Unlock()
+	if backgroundFlusher == nil {
+		return
+	}
+
+	now := time.Now()
+	l := &Log{
+		ID:        uuid.NewString(),
+		AppName:   backgroundAppName,
+		Timestamp: now,
+		Entries: []Entry{{
+			Timestamp: now,
+			Level:     level,
+			Layer:     layer,
+			Message:   msg,
+			Data:      data,
+		}},
+	}
+	if err := backgroundFlusher.Flush(ctx, l); err != nil {
+		fmt.Printf("error flushing log: %v\n", err)
+	}
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+type recordingFlusher struct {
+	logs []*Log
+}
+
+func (f *recordingFlusher) Flush(ctx context.Context, log *Log) error {
+	f.logs = append(f.logs, log)
+	return nil
+}
+
+func TestRecord(t *testing.T) {
+	flusher := &recordingFlusher{}
+	SetBackgroundFlusher(flusher, "songs")
+	defer SetBackgroundFlusher(&JSONFlusher{writer: os.Stderr}, "")
+
+	Record(context.Background(), Warn, LayerApp, "outside a request", nil)
+	if len(flusher.logs) != 1 || flusher.logs[0].AppName != "songs" || flusher.logs[0].Entries[0].Message != "outside a request" {
+		t.Fatalf("background logs = %+v", flusher.logs)
+	}
+
+	requestLog := &Log{}
+	Record(WithLog(context.Background(), requestLog), Info, LayerHandler, "inside a request", nil)
+	if len(requestLog.Entries) != 1 || len(flusher.logs) != 1 {
+		t.Fatalf("request entries = %+v, background logs = %d", requestLog.Entries, len(flusher.logs))
+	}
+}
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/env"
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+type CompressionConfig struct {
+	// Encodings in order of preference. Supported values are "br" and "gzip".
+	Encodings []string
+	Level     int
+	// MinSize is the minimum response size in bytes that gets compressed.
+	MinSize int
+	// ContentTypes are matched as prefixes, e.g. "text/" or "application/json".
+	ContentTypes []string
+}
+
+func LoadCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Encodings:    env.GetEnvSlice("COMPRESSION_ENCODINGS", []string{EncodingBrotli, EncodingGzip}),
+		Level:        env.GetEnvInt("COMPRESSION_LEVEL", 5),
+		MinSize:      env.GetEnvInt("COMPRESSION_MIN_SIZE", 1024),
+		ContentTypes: env.GetEnvSlice("COMPRESSION_CONTENT_TYPES", []string{"text/", "application/json", "application/problem+json", "application/javascript", "application/xml", "image/svg+xml"}),
+	}
+}
+
+func Compress(cfg CompressionConfig) func(http.Handler) http.Handler {
+	pools := map[string]*sync.Pool{
+		EncodingGzip: {New: func() any {
+			w, err := gzip.NewWriterLevel(io.Discard, cfg.Level)
+			if err != nil {
+				w = gzip.NewWriter(io.Discard)
+			}
+			return w
+		}},
+		EncodingBrotli: {New: func() any {
+			return brotli.NewWriterLevel(io.Discard, cfg.Level)
+		}},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), cfg.Encodings)
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				cfg:            cfg,
+				encoding:       encoding,
+				pool:           pools[encoding],
+				status:         http.StatusOK,
+			}
+			defer cw.Close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	cfg      CompressionConfig
+	encoding string
+	pool     *sync.Pool
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder encoder
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.decided {
+		return
+	}
+	if status < 200 {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	cw.status = status
+	if status == http.StatusNoContent || status == http.StatusNotModified || status == http.StatusPartialContent {
+		_ = cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.decided {
+		cw.buf = append(cw.buf, b...)
+		if len(cw.buf) < cw.cfg.MinSize {
+			return len(b), nil
+		}
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		_ = cw.decide(len(cw.buf) >= cw.cfg.MinSize)
+	}
+	if cw.encoder != nil {
+		_ = cw.encoder.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if err := cw.decide(len(cw.buf) >= cw.cfg.MinSize); err != nil {
+			return err
+		}
+	}
+	if cw.encoder == nil {
+		return nil
+	}
+	err := cw.encoder.Close()
+	cw.encoder.Reset(io.Discard)
+	cw.pool.Put(cw.encoder)
+	cw.encoder = nil
+	return err
+}
+
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	header := cw.Header()
+
+	if header.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+
+	if compress && header.Get("Content-Encoding") == "" && cw.compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		cw.encoder = cw.pool.Get().(encoder)
+		cw.encoder.Reset(cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	buf := cw.buf
+	cw.buf = nil
+	if cw.encoder != nil {
+		_, err := cw.encoder.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+func (cw *compressWriter) compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, allowed := range cw.cfg.ContentTypes {
+		if strings.HasPrefix(contentType, strings.ToLower(allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+func negotiateEncoding(acceptEncoding string, preferred []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoding := range preferred {
+		quality, ok := accepted[encoding]
+		if !ok {
+			quality, ok = accepted["*"]
+		}
+		if ok && quality > bestQuality && (encoding == EncodingGzip || encoding == EncodingBrotli) {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
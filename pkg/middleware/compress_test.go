@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	preferred := []string{EncodingBrotli, EncodingGzip}
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", EncodingGzip},
+		{"gzip, br", EncodingBrotli},
+		{"gzip;q=1.0, br;q=0.5", EncodingGzip},
+		{"br;q=0, gzip", EncodingGzip},
+		{"*", EncodingBrotli},
+		{"GZIP", EncodingGzip},
+		{"deflate, compress", ""},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.acceptEncoding, preferred); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func decode(t *testing.T, encoding string, body io.Reader) string {
+	t.Helper()
+	switch encoding {
+	case EncodingGzip:
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body = reader
+	case EncodingBrotli:
+		body = brotli.NewReader(body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestCompress(t *testing.T) {
+	cfg := CompressionConfig{
+		Encodings:    []string{EncodingBrotli, EncodingGzip},
+		Level:        5,
+		MinSize:      16,
+		ContentTypes: []string{"text/", "application/json"},
+	}
+	large := strings.Repeat("melodia ", 32)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		rangeHeader    string
+		handler        http.HandlerFunc
+		wantEncoding   string
+		wantStatus     int
+		wantBody       string
+	}{
+		{
+			name:           "gzip",
+			acceptEncoding: "gzip",
+			handler:        func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, large) },
+			wantEncoding:   EncodingGzip,
+			wantBody:       large,
+		},
+		{
+			name:           "brotli in small writes",
+			acceptEncoding: "br, gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				for _, word := range strings.Fields(large) {
+					io.WriteString(w, word+" ")
+				}
+			},
+			wantEncoding: EncodingBrotli,
+			wantStatus:   http.StatusCreated,
+			wantBody:     large,
+		},
+		{
+			name:           "below MinSize",
+			acceptEncoding: "gzip",
+			handler:        func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "short") },
+			wantBody:       "short",
+		},
+		{
+			name:           "not compressible",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				io.WriteString(w, large)
+			},
+			wantBody: large,
+		},
+		{
+			name:           "already encoded",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("Content-Encoding", "identity")
+				io.WriteString(w, large)
+			},
+			wantEncoding: "identity",
+			wantBody:     large,
+		},
+		{
+			name:           "range request",
+			acceptEncoding: "gzip",
+			rangeHeader:    "bytes=0-10",
+			handler:        func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, large) },
+			wantBody:       large,
+		},
+		{
+			name:           "not accepted",
+			acceptEncoding: "deflate",
+			handler:        func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, large) },
+			wantBody:       large,
+		},
+		{
+			name:           "no content",
+			acceptEncoding: "gzip",
+			handler:        func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) },
+			wantStatus:     http.StatusNoContent,
+		},
+		{
+			name:           "flushed early",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, "data: 1\n\n")
+				w.(http.Flusher).Flush()
+				io.WriteString(w, large)
+			},
+			wantBody: "data: 1\n\n" + large,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			if tt.rangeHeader != "" {
+				r.Header.Set("Range", tt.rangeHeader)
+			}
+			w := httptest.NewRecorder()
+			Compress(cfg)(tt.handler).ServeHTTP(w, r)
+
+			wantStatus := tt.wantStatus
+			if wantStatus == 0 {
+				wantStatus = http.StatusOK
+			}
+			if w.Code != wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, wantStatus)
+			}
+			encoding := w.Header().Get("Content-Encoding")
+			if encoding != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", encoding, tt.wantEncoding)
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Vary = %q", w.Header().Get("Vary"))
+			}
+			if body := decode(t, encoding, w.Body); body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCompressDetectsContentType(t *testing.T) {
+	cfg := CompressionConfig{Encodings: []string{EncodingGzip}, Level: 5, MinSize: 1, ContentTypes: []string{"text/html"}}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	Compress(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html><body>melodia</body></html>")
+	})).ServeHTTP(w, r)
+
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") || w.Header().Get("Content-Encoding") != EncodingGzip {
+		t.Errorf("headers = %v", w.Header())
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/env"
+)
+
+type CORSConfig struct {
+	// AllowedOrigins accepts exact origins, "*" and wildcards such as "https://*.melodia.com".
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+func LoadCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins:   env.GetEnvSlice("CORS_ALLOWED_ORIGINS", []string{}),
+		AllowedMethods:   env.GetEnvSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+		AllowedHeaders:   env.GetEnvSlice("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type", "Idempotency-Key"}),
+		ExposedHeaders:   env.GetEnvSlice("CORS_EXPOSED_HEADERS", []string{}),
+		AllowCredentials: env.GetEnvBool("CORS_ALLOW_CREDENTIALS", false),
+		MaxAge:           env.GetEnvDuration("CORS_MAX_AGE", 10*time.Minute),
+	}
+}
+
+func CORS(cfg CORSConfig) func(http.Handler) http.Handler {
+	methods := strings.Join(upper(cfg.AllowedMethods), ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	allowAll := slices.Contains(cfg.AllowedOrigins, "*")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			w.Header().Add("Vary", "Origin")
+
+			if origin == "" || !(allowAll || originAllowed(cfg.AllowedOrigins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowAll && !cfg.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			isPreflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !isPreflight {
+				if exposed != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposed)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				w.Header().Set("Access-Control-Allow-Headers", requested)
+			}
+			if cfg.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+func originAllowed(allowed []string, origin string) bool {
+	for _, pattern := range allowed {
+		if strings.EqualFold(pattern, origin) {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(pattern, "*"); ok {
+			lower := strings.ToLower(origin)
+			if len(lower) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(lower, strings.ToLower(prefix)) &&
+				strings.HasSuffix(lower, strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func upper(values []string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = strings.ToUpper(v)
+	}
+	return result
+}
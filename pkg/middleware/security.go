@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/env"
+)
+
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge disables Strict-Transport-Security when zero. It is only sent over HTTPS.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	ContentTypeNosniff    bool
+	// FrameOptions is DENY or SAMEORIGIN. Empty disables the header.
+	FrameOptions          string
+	ContentSecurityPolicy string
+	ReferrerPolicy        string
+}
+
+func LoadSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		HSTSMaxAge:            env.GetEnvDuration("SECURITY_HSTS_MAX_AGE", 365*24*time.Hour),
+		HSTSIncludeSubdomains: env.GetEnvBool("SECURITY_HSTS_INCLUDE_SUBDOMAINS", true),
+		HSTSPreload:           env.GetEnvBool("SECURITY_HSTS_PRELOAD", false),
+		ContentTypeNosniff:    env.GetEnvBool("SECURITY_CONTENT_TYPE_NOSNIFF", true),
+		FrameOptions:          env.GetEnv("SECURITY_FRAME_OPTIONS", "DENY"),
+		ContentSecurityPolicy: env.GetEnv("SECURITY_CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'"),
+		ReferrerPolicy:        env.GetEnv("SECURITY_REFERRER_POLICY", "no-referrer"),
+	}
+}
+
+func SecurityHeaders(cfg SecurityHeadersConfig) func(http.Handler) http.Handler {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			if hsts != "" && isHTTPS(r) {
+				header.Set("Strict-Transport-Security", hsts)
+			}
+			if cfg.ContentTypeNosniff {
+				header.Set("X-Content-Type-Options", "nosniff")
+			}
+			if cfg.FrameOptions != "" {
+				header.Set("X-Frame-Options", strings.ToUpper(cfg.FrameOptions))
+			}
+			if cfg.ContentSecurityPolicy != "" {
+				header.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+			}
+			if cfg.ReferrerPolicy != "" {
+				header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
@@ -0,0 +1,31 @@
+package minio
+
+import (
+	"context"
+	"maps"
+	"mime/multipart"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/audio"
+	"github.com/minio/minio-go/v7"
+)
+
+// UploadAudio inspects an audio form file and uploads it with UploadFileHeader, with the detected
+// content type and the format, duration, bitrate, sample rate, channels and tags as user metadata.
+// Files not in the allowed formats or corrupted are rejected with a validation AppError before
+// anything is uploaded. No allowed formats allows all the formats audio.Inspect recognizes.
+func UploadAudio(ctx context.Context, bucket MinioBucket, fileName string, fileHeader *multipart.FileHeader, opts minio.PutObjectOptions, allowed ...audio.Format) (audio.Info, error) {
+	info, err := audio.InspectFileHeader(fileHeader, allowed...)
+	if err != nil {
+		return audio.Info{}, err
+	}
+
+	opts.ContentType = info.ContentType
+	metadata := info.Metadata()
+	maps.Copy(metadata, opts.UserMetadata)
+	opts.UserMetadata = metadata
+
+	if err := bucket.UploadFileHeader(ctx, fileName, fileHeader, opts); err != nil {
+		return audio.Info{}, err
+	}
+	return info, nil
+}
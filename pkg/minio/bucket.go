@@ -7,17 +7,32 @@ import (
 	"io"
 	"mime/multipart"
 
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/logger"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
 	"github.com/minio/minio-go/v7"
 )
 
+// ErrUnsupportedOption is returned by uploads with options of minio.PutObjectOptions the bucket cannot apply.
+var ErrUnsupportedOption = errors.New("upload option not supported by this bucket")
+
+// MinioBucketImpl is a MinioBucket on a MinIO or S3 bucket. Objects are read through objectstore.NewS3
+// and uploaded with every option of minio.PutObjectOptions, unless the bucket is content addressed.
 type MinioBucketImpl struct {
-	client         *minio.Client
-	bucketName     string
-	publicEndpoint string
-	useSSL         bool
+	*storeBucket
+	client     *minio.Client
+	bucketName string
+	// direct is false when uploads must go through the store, e.g. to be content addressed.
+	direct bool
 }
 
-func NewMinioBucket(client *minio.Client, bucketName string, publicEndpoint string, useSSL bool) (MinioBucket, error) {
+// NewMinioBucket creates the bucket with the declared access, versioning, object lock and encryption
+// when it does not exist. For an existing bucket only the policy is checked against the declared one.
+func NewMinioBucket(client *minio.Client, bucketName string, publicEndpoint string, useSSL bool, opts ...BucketOption) (MinioBucket, error) {
+	cfg := &bucketConfig{presignClient: client, access: PublicRead()}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	if client == nil {
 		return nil, errors.New("client is nil")
 	}
@@ -25,76 +40,121 @@ func NewMinioBucket(client *minio.Client, bucketName string, publicEndpoint stri
 		return nil, errors.New("bucket name is empty")
 	}
 
-	exists, err := client.BucketExists(context.Background(), bucketName)
+	policy, err := cfg.access.Policy(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, bucketName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check bucket existence: %w", err)
 	}
 
 	if !exists {
-		err = client.MakeBucket(context.Background(), bucketName, minio.MakeBucketOptions{})
-		if err != nil {
-			return nil, fmt.Errorf("failed to create bucket: %w", err)
-		}
-
-		policy := fmt.Sprintf(`{
-            "Version": "2012-10-17",
-            "Statement": [
-                {
-                    "Effect": "Allow",
-                    "Principal": {"AWS": ["*"]},
-                    "Action": ["s3:GetObject"],
-                    "Resource": ["arn:aws:s3:::%s/*"]
-                }
-            ]
-        }`, bucketName)
-
-		err = client.SetBucketPolicy(context.Background(), bucketName, policy)
-		if err != nil {
-			return nil, fmt.Errorf("failed to set bucket policy: %w", err)
+		if err := createBucket(ctx, client, bucketName, policy, cfg); err != nil {
+			return nil, err
 		}
+	} else if err := checkBucketPolicy(ctx, client, bucketName, policy, cfg); err != nil {
+		return nil, err
 	}
 
+	protocol := "http"
+	if useSSL {
+		protocol = "https"
+	}
+	bucketURL := fmt.Sprintf("%s://%s/%s", protocol, publicEndpoint, bucketName)
+	store := objectstore.NewS3(client, bucketName, bucketURL, objectstore.WithPresignClient(cfg.presignClient))
+	if cfg.contentAddressed {
+		store = objectstore.NewContentAddressed(store, cfg.casOptions...)
+	}
 	return &MinioBucketImpl{
-		client:         client,
-		bucketName:     bucketName,
-		publicEndpoint: publicEndpoint,
-		useSSL:         useSSL,
+		storeBucket: &storeBucket{store: store},
+		client:      client,
+		bucketName:  bucketName,
+		direct:      !cfg.contentAddressed,
 	}, nil
 }
 
+// UploadFile passes opts to the client as they are. Streams of unknown size are uploaded in parts of
+// 16MB unless opts.PartSize is set.
 func (b *MinioBucketImpl) UploadFile(ctx context.Context, fileName string, file io.Reader, fileSize int64, opts minio.PutObjectOptions) error {
+	if !b.direct {
+		return b.storeBucket.UploadFile(ctx, fileName, file, fileSize, opts)
+	}
+	if fileSize < 0 && opts.PartSize == 0 {
+		opts.PartSize = defaultStreamPartSize
+	}
 	_, err := b.client.PutObject(ctx, b.bucketName, fileName, file, fileSize, opts)
+	return err
+}
+
+func (b *MinioBucketImpl) UploadFileHeader(ctx context.Context, fileName string, fileHeader *multipart.FileHeader, opts minio.PutObjectOptions) error {
+	return uploadFileHeader(ctx, b, fileName, fileHeader, opts)
+}
+
+func createBucket(ctx context.Context, client *minio.Client, bucketName string, policy string, cfg *bucketConfig) error {
+	err := client.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{ObjectLocking: cfg.objectLock})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to create bucket: %w", err)
+	}
+
+	if policy != "" {
+		if err := client.SetBucketPolicy(ctx, bucketName, policy); err != nil {
+			return fmt.Errorf("failed to set bucket policy: %w", err)
+		}
+	}
+
+	if cfg.versioning && !cfg.objectLock {
+		if err := client.EnableVersioning(ctx, bucketName); err != nil {
+			return fmt.Errorf("failed to enable versioning: %w", err)
+		}
+	}
+
+	if cfg.objectLock && cfg.retentionDays > 0 {
+		unit := minio.Days
+		if err := client.SetObjectLockConfig(ctx, bucketName, &cfg.retentionMode, &cfg.retentionDays, &unit); err != nil {
+			return fmt.Errorf("failed to set object lock: %w", err)
+		}
+	}
+
+	if cfg.encryption != nil {
+		if err := client.SetBucketEncryption(ctx, bucketName, cfg.encryption); err != nil {
+			return fmt.Errorf("failed to set bucket encryption: %w", err)
+		}
 	}
 	return nil
 }
 
-func (b *MinioBucketImpl) DownloadFile(ctx context.Context, fileName string) (ObjectData, error) {
-	obj, err := b.client.GetObject(ctx, b.bucketName, fileName, minio.GetObjectOptions{})
+func checkBucketPolicy(ctx context.Context, client *minio.Client, bucketName string, policy string, cfg *bucketConfig) error {
+	current, err := client.GetBucketPolicy(ctx, bucketName)
 	if err != nil {
-		return ObjectData{}, err
+		return fmt.Errorf("failed to get bucket policy: %w", err)
 	}
 
-	info, err := obj.Stat()
+	same, err := samePolicy(current, policy)
 	if err != nil {
-		return ObjectData{}, err
+		return fmt.Errorf("failed to compare bucket policy: %w", err)
+	}
+	if same {
+		return nil
 	}
 
-	return ObjectData{
-		Reader:       obj,
-		ContentType:  info.ContentType,
-		FileName:     info.UserMetadata["original_filename"],
-		Size:         info.Size,
-		UserMetadata: info.UserMetadata,
-	}, nil
-}
-
-func (b *MinioBucketImpl) DeleteFile(ctx context.Context, fileName string) error {
-	return b.client.RemoveObject(ctx, b.bucketName, fileName, minio.RemoveObjectOptions{})
+	switch cfg.onMismatch {
+	case PolicyMismatchReconcile:
+		if err := client.SetBucketPolicy(ctx, bucketName, policy); err != nil {
+			return fmt.Errorf("failed to reconcile bucket policy: %w", err)
+		}
+	case PolicyMismatchFail:
+		return fmt.Errorf("bucket %s policy does not match the declared %s access", bucketName, cfg.access)
+	default:
+		logger.Record(ctx, logger.Warn, logger.LayerApp, fmt.Sprintf("bucket %s policy does not match the declared %s access", bucketName, cfg.access), nil)
+	}
+	return nil
 }
 
-func (b *MinioBucketImpl) UploadFileHeader(ctx context.Context, fileName string, fileHeader *multipart.FileHeader, opts minio.PutObjectOptions) error {
+// uploadFileHeader uploads a form file with its content type and its name as original_filename.
+func uploadFileHeader(ctx context.Context, bucket MinioBucket, fileName string, fileHeader *multipart.FileHeader, opts minio.PutObjectOptions) error {
 	file, err := fileHeader.Open()
 	if err != nil {
 		return err
@@ -110,18 +170,5 @@ func (b *MinioBucketImpl) UploadFileHeader(ctx context.Context, fileName string,
 	}
 	opts.UserMetadata["original_filename"] = fileHeader.Filename
 
-	return b.UploadFile(ctx, fileName, file, fileHeader.Size, opts)
-}
-
-func (b *MinioBucketImpl) GetObjectURL(objectName string) string {
-	if objectName == "" {
-		return ""
-	}
-
-	protocol := "http"
-	if b.useSSL {
-		protocol = "https"
-	}
-
-	return fmt.Sprintf("%s://%s/%s/%s", protocol, b.publicEndpoint, b.bucketName, objectName)
+	return bucket.UploadFile(ctx, fileName, file, fileHeader.Size, opts)
 }
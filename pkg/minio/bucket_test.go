@@ -0,0 +1,105 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+// recordingS3 accepts single part uploads and records their headers.
+type recordingS3 struct {
+	mutex   sync.Mutex
+	headers http.Header
+}
+
+func (s *recordingS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "unexpected request", http.StatusNotImplemented)
+		return
+	}
+	_, _ = io.Copy(io.Discard, r.Body)
+	s.mutex.Lock()
+	s.headers = r.Header.Clone()
+	s.mutex.Unlock()
+	w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+}
+
+func newTestMinioBucket(t *testing.T, handler http.Handler) *MinioBucketImpl {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := minio.New(strings.TrimPrefix(server.URL, "http://"), &minio.Options{
+		Creds:  credentials.NewStaticV4("access", "secret", ""),
+		Region: "us-east-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := objectstore.NewS3(client, "songs", server.URL+"/songs")
+	return &MinioBucketImpl{storeBucket: &storeBucket{store: store}, client: client, bucketName: "songs", direct: true}
+}
+
+func TestMinioBucketUploadOptions(t *testing.T) {
+	server := &recordingS3{}
+	bucket := newTestMinioBucket(t, server)
+
+	err := bucket.UploadFile(context.Background(), "a.mp3", strings.NewReader("audio"), 5, minio.PutObjectOptions{
+		ContentType:          "audio/mpeg",
+		ContentLanguage:      "es",
+		StorageClass:         "REDUCED_REDUNDANCY",
+		ServerSideEncryption: encrypt.NewSSE(),
+		UserMetadata:         map[string]string{"original_filename": "a.mp3"},
+	})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	tests := map[string]string{
+		"Content-Type":                 "audio/mpeg",
+		"Content-Language":             "es",
+		"X-Amz-Storage-Class":          "REDUCED_REDUNDANCY",
+		"X-Amz-Server-Side-Encryption": "AES256",
+		"X-Amz-Meta-Original_filename": "a.mp3",
+	}
+	for header, want := range tests {
+		if got := server.headers.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestStoreBucketUnsupportedOptions(t *testing.T) {
+	bucket := NewMemoryBucket("")
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		opts    minio.PutObjectOptions
+		wantErr bool
+	}{
+		{"supported", minio.PutObjectOptions{ContentType: "text/plain", CacheControl: "no-cache", UserTags: map[string]string{"a": "b"}}, false},
+		{"transfer settings", minio.PutObjectOptions{NumThreads: 4, PartSize: 5 << 20, SendContentMd5: true}, false},
+		{"encryption", minio.PutObjectOptions{ServerSideEncryption: encrypt.NewSSE()}, true},
+		{"storage class", minio.PutObjectOptions{StorageClass: "GLACIER"}, true},
+		{"content encoding", minio.PutObjectOptions{ContentEncoding: "gzip"}, true},
+		{"legal hold", minio.PutObjectOptions{LegalHold: minio.LegalHoldEnabled}, true},
+	}
+
+	for _, tt := range tests {
+		err := bucket.UploadFile(ctx, "a.txt", strings.NewReader("a"), 1, tt.opts)
+		if errors.Is(err, ErrUnsupportedOption) != tt.wantErr || (err != nil && !tt.wantErr) {
+			t.Errorf("%s: error = %v", tt.name, err)
+		}
+	}
+}
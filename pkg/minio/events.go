@@ -0,0 +1,404 @@
+package minio
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"sync"
+	"time"
+
+	pkgCtx "github.com/Melodia-IS2/melodia-go-utils/pkg/ctx"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+type ObjectEventType string
+
+const (
+	ObjectCreated ObjectEventType = "ObjectCreated"
+	ObjectDeleted ObjectEventType = "ObjectDeleted"
+)
+
+// DefaultEventsTopic is the topic events are published to when WithEventsTopic is not used.
+const DefaultEventsTopic = "object-events"
+
+// ObjectEvent is published as JSON with the object key as the message key, so the events of an
+// object keep their order within a partition.
+type ObjectEvent struct {
+	ID          string          `json:"id"`
+	Type        ObjectEventType `json:"type"`
+	Bucket      string          `json:"bucket,omitempty"`
+	Key         string          `json:"key"`
+	Size        int64           `json:"size"`
+	ContentType string          `json:"content_type,omitempty"`
+	// Checksum is the hex SHA-256 of the content, empty when it is not known, e.g. for deletes.
+	Checksum string    `json:"checksum,omitempty"`
+	UserID   string    `json:"user_id,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Publisher sends a message to a topic. Adapt the melodia-events Kafka publisher, or any other
+// producer, with PublisherFunc. Pass key as the message key, so the events of an object go to the
+// same partition and are consumed in order:
+//
+//	publisher := minio.PublisherFunc(func(ctx context.Context, topic, key string, value []byte) error {
+//		return kafkaPublisher.Publish(ctx, topic, key, value)
+//	})
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key string, value []byte) error
+}
+
+type PublisherFunc func(ctx context.Context, topic string, key string, value []byte) error
+
+func (f PublisherFunc) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	return f(ctx, topic, key, value)
+}
+
+type eventsConfig struct {
+	topic   string
+	bucket  string
+	outbox  Outbox
+	timeout time.Duration
+}
+
+type EventsOption func(cfg *eventsConfig)
+
+// WithEventsTopic sets the topic events are published to. Defaults to DefaultEventsTopic.
+func WithEventsTopic(topic string) EventsOption {
+	return func(cfg *eventsConfig) {
+		cfg.topic = topic
+	}
+}
+
+// WithEventsBucketName sets the bucket name included in the events, for consumers of several buckets.
+func WithEventsBucketName(name string) EventsOption {
+	return func(cfg *eventsConfig) {
+		cfg.bucket = name
+	}
+}
+
+// WithOutbox stores the events that cannot be published in outbox, for an OutboxRelay to publish
+// them when the broker is back. The events of an object with events still in the outbox are stored
+// there too, so they are not published before the earlier ones. Without an outbox a failed publish
+// is returned as an error.
+func WithOutbox(outbox Outbox) EventsOption {
+	return func(cfg *eventsConfig) {
+		cfg.outbox = outbox
+	}
+}
+
+// WithPublishTimeout bounds how long a write waits for the broker before falling back to the
+// outbox. Defaults to 5 seconds.
+func WithPublishTimeout(timeout time.Duration) EventsOption {
+	return func(cfg *eventsConfig) {
+		cfg.timeout = timeout
+	}
+}
+
+// EventPublishError is returned by the writes of an events bucket when the object was written but
+// its events could not be published nor stored in the outbox. Retrying the write is safe.
+type EventPublishError struct {
+	Events []ObjectEvent
+	Err    error
+}
+
+func (e *EventPublishError) Error() string {
+	return fmt.Sprintf("object written but %d event(s) not published: %v", len(e.Events), e.Err)
+}
+
+func (e *EventPublishError) Unwrap() error {
+	return e.Err
+}
+
+type eventBucket struct {
+	MinioBucket
+	publisher Publisher
+	cfg       eventsConfig
+	keys      keyLocks
+}
+
+// NewEventBucket wraps bucket to publish an ObjectCreated event after every successful upload or
+// copy and an ObjectDeleted event after every successful delete. Failed writes publish nothing.
+// The uploader is the user ID of the context, as set by the auth middleware. Writes made through
+// Store() bypass the wrapper and publish nothing.
+func NewEventBucket(bucket MinioBucket, publisher Publisher, opts ...EventsOption) MinioBucket {
+	cfg := eventsConfig{
+		topic:   DefaultEventsTopic,
+		timeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &eventBucket{MinioBucket: bucket, publisher: publisher, cfg: cfg}
+}
+
+func (b *eventBucket) UploadFile(ctx context.Context, fileName string, file io.Reader, fileSize int64, opts minio.PutObjectOptions) error {
+	hash := sha256.New()
+	counter := &countingReader{reader: io.TeeReader(file, hash)}
+	if err := b.MinioBucket.UploadFile(ctx, fileName, counter, fileSize, opts); err != nil {
+		return err
+	}
+
+	event := b.newEvent(ctx, ObjectCreated, fileName)
+	event.Size = counter.count
+	event.ContentType = opts.ContentType
+	event.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return b.emit(ctx, event)
+}
+
+func (b *eventBucket) UploadFileHeader(ctx context.Context, fileName string, fileHeader *multipart.FileHeader, opts minio.PutObjectOptions) error {
+	return uploadFileHeader(ctx, b, fileName, fileHeader, opts)
+}
+
+func (b *eventBucket) DeleteFile(ctx context.Context, fileName string) error {
+	info, err := b.MinioBucket.Stat(ctx, fileName)
+	if errors.Is(err, ErrObjectNotFound) {
+		// Nothing to delete, so nothing to notify.
+		return b.MinioBucket.DeleteFile(ctx, fileName)
+	}
+	if err != nil {
+		return err
+	}
+	if err := b.MinioBucket.DeleteFile(ctx, fileName); err != nil {
+		return err
+	}
+	return b.emit(ctx, b.deletedEvent(ctx, info))
+}
+
+func (b *eventBucket) Copy(ctx context.Context, src string, dst string) error {
+	if err := b.MinioBucket.Copy(ctx, src, dst); err != nil {
+		return err
+	}
+	info, err := b.MinioBucket.Stat(ctx, dst)
+	if err != nil {
+		return err
+	}
+
+	event := b.newEvent(ctx, ObjectCreated, dst)
+	event.Size = info.Size
+	event.ContentType = info.ContentType
+	event.Checksum = info.UserMetadata[objectstore.MetadataSHA256]
+	return b.emit(ctx, event)
+}
+
+// Move copies and deletes through the wrapper, so it publishes a created and a deleted event.
+func (b *eventBucket) Move(ctx context.Context, src string, dst string) error {
+	if src == dst {
+		return nil
+	}
+	if err := b.Copy(ctx, src, dst); err != nil {
+		return err
+	}
+	return b.DeleteFile(ctx, src)
+}
+
+func (b *eventBucket) DeleteMany(ctx context.Context, objectNames []string) error {
+	var objects []ObjectInfo
+	for _, name := range objectNames {
+		info, err := b.MinioBucket.Stat(ctx, name)
+		if errors.Is(err, ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		objects = append(objects, info)
+	}
+	return b.deleteObjects(ctx, objectNames, objects)
+}
+
+func (b *eventBucket) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	if prefix == "" {
+		return 0, fmt.Errorf("prefix is empty, refusing to delete the whole bucket")
+	}
+
+	var objects []ObjectInfo
+	var names []string
+	for object, err := range b.MinioBucket.List(ctx, prefix) {
+		if err != nil {
+			return 0, err
+		}
+		objects = append(objects, object)
+		names = append(names, object.Key)
+	}
+
+	err := b.deleteObjects(ctx, names, objects)
+	var failed *BatchDeleteError
+	if errors.As(err, &failed) {
+		return len(names) - len(failed.Errors), err
+	}
+	if err != nil {
+		var publishErr *EventPublishError
+		if errors.As(err, &publishErr) {
+			return len(names), err
+		}
+		return 0, err
+	}
+	return len(names), nil
+}
+
+// deleteObjects deletes names and publishes the deleted events of the objects that were removed.
+func (b *eventBucket) deleteObjects(ctx context.Context, names []string, objects []ObjectInfo) error {
+	deleteErr := b.MinioBucket.DeleteMany(ctx, names)
+	var failed *BatchDeleteError
+	if deleteErr != nil && !errors.As(deleteErr, &failed) {
+		return deleteErr
+	}
+
+	var events []ObjectEvent
+	for _, object := range objects {
+		if failed != nil {
+			if _, ok := failed.Errors[object.Key]; ok {
+				continue
+			}
+		}
+		events = append(events, b.deletedEvent(ctx, object))
+	}
+	if err := b.emit(ctx, events...); err != nil {
+		return errors.Join(deleteErr, err)
+	}
+	return deleteErr
+}
+
+func (b *eventBucket) newEvent(ctx context.Context, eventType ObjectEventType, key string) ObjectEvent {
+	event := ObjectEvent{
+		ID:     uuid.NewString(),
+		Type:   eventType,
+		Bucket: b.cfg.bucket,
+		Key:    key,
+		Time:   time.Now().UTC(),
+	}
+	if userID, err := pkgCtx.GetUserID(ctx); err == nil {
+		event.UserID = userID.String()
+	}
+	return event
+}
+
+func (b *eventBucket) deletedEvent(ctx context.Context, info ObjectInfo) ObjectEvent {
+	event := b.newEvent(ctx, ObjectDeleted, info.Key)
+	event.Size = info.Size
+	event.ContentType = info.ContentType
+	event.Checksum = info.UserMetadata[objectstore.MetadataSHA256]
+	return event
+}
+
+// emit publishes events, storing the ones that fail in the outbox. The write already happened, so
+// the context is detached from the request cancellation.
+func (b *eventBucket) emit(ctx context.Context, events ...ObjectEvent) error {
+	ctx = context.WithoutCancel(ctx)
+
+	var unsent []ObjectEvent
+	var errs []error
+	for _, event := range events {
+		message, err := event.OutboxMessage(b.cfg.topic)
+		if err != nil {
+			return err
+		}
+		if err := b.send(ctx, message); err != nil {
+			unsent = append(unsent, event)
+			errs = append(errs, err)
+		}
+	}
+	if len(unsent) > 0 {
+		return &EventPublishError{Events: unsent, Err: errors.Join(errs...)}
+	}
+	return nil
+}
+
+// send publishes message, or stores it in the outbox when the publish fails or when earlier
+// messages with the same key are still in the outbox, so the relay publishes them in order. The
+// sends of a key are serialized, so a message is not published while an earlier one is on its way
+// to the outbox. Other processes sharing the outbox are not coordinated.
+func (b *eventBucket) send(ctx context.Context, message OutboxMessage) error {
+	if b.cfg.outbox == nil {
+		return b.publish(ctx, message)
+	}
+	unlock := b.keys.lock(message.Topic + "\x00" + message.Key)
+	defer unlock()
+
+	pending, err := b.cfg.outbox.HasPending(ctx, message.Topic, message.Key)
+	if err != nil {
+		return err
+	}
+	if !pending && b.publish(ctx, message) == nil {
+		return nil
+	}
+	return b.cfg.outbox.Add(ctx, message)
+}
+
+func (b *eventBucket) publish(ctx context.Context, message OutboxMessage) error {
+	if b.cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.cfg.timeout)
+		defer cancel()
+	}
+	return b.publisher.Publish(ctx, message.Topic, message.Key, message.Value)
+}
+
+// OutboxMessage returns the message published for the event, e.g. to store it with MySQLOutbox.AddTx.
+func (event ObjectEvent) OutboxMessage(topic string) (OutboxMessage, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+	return OutboxMessage{
+		ID:        event.ID,
+		Topic:     topic,
+		Key:       event.Key,
+		Value:     value,
+		CreatedAt: event.Time,
+	}, nil
+}
+
+// keyLocks is a mutex per key, removed once nobody holds or waits for it.
+type keyLocks struct {
+	mutex sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (l *keyLocks) lock(key string) (unlock func()) {
+	l.mutex.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*keyLock{}
+	}
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &keyLock{}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mutex.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mutex.Unlock()
+	}
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,338 @@
+package minio
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// recordingPublisher records the published events and fails while down is set.
+type recordingPublisher struct {
+	mutex  sync.Mutex
+	down   bool
+	events []ObjectEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.down {
+		return errors.New("broker unavailable")
+	}
+	var event ObjectEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return err
+	}
+	if topic != DefaultEventsTopic || key != event.Key {
+		return errors.New("unexpected topic or key")
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) setDown(down bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.down = down
+}
+
+// published returns the type and key of the events published since the last call.
+func (p *recordingPublisher) published() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var result []string
+	for _, event := range p.events {
+		result = append(result, string(event.Type)+" "+event.Key)
+	}
+	p.events = nil
+	return result
+}
+
+func upload(t *testing.T, bucket MinioBucket, name string, content string) error {
+	t.Helper()
+	return bucket.UploadFile(context.Background(), name, strings.NewReader(content), int64(len(content)), minio.PutObjectOptions{ContentType: "text/plain"})
+}
+
+func TestEventBucket(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bucket := NewEventBucket(NewMemoryBucket("http://localhost/files"), publisher, WithEventsBucketName("songs"))
+	ctx := context.Background()
+
+	if err := upload(t, bucket, "a.txt", "content"); err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.events) != 1 {
+		t.Fatalf("upload published %d events, want 1", len(publisher.events))
+	}
+	sum := sha256.Sum256([]byte("content"))
+	event := publisher.events[0]
+	if event.Type != ObjectCreated || event.Bucket != "songs" || event.Size != 7 || event.ContentType != "text/plain" ||
+		event.Checksum != hex.EncodeToString(sum[:]) || event.ID == "" || event.Time.IsZero() {
+		t.Errorf("created event = %+v", event)
+	}
+	publisher.published()
+
+	tests := []struct {
+		name  string
+		write func() error
+		want  []string
+	}{
+		{"copy", func() error { return bucket.Copy(ctx, "a.txt", "b.txt") }, []string{"ObjectCreated b.txt"}},
+		{"move", func() error { return bucket.Move(ctx, "b.txt", "c.txt") }, []string{"ObjectCreated c.txt", "ObjectDeleted b.txt"}},
+		{"delete", func() error { return bucket.DeleteFile(ctx, "c.txt") }, []string{"ObjectDeleted c.txt"}},
+		{"delete missing", func() error { return bucket.DeleteFile(ctx, "c.txt") }, nil},
+		{"failed upload", func() error {
+			if err := upload(t, bucket, "../x", "content"); err == nil {
+				return errors.New("upload of an invalid name succeeded")
+			}
+			return nil
+		}, nil},
+		{"delete many", func() error {
+			if err := upload(t, bucket, "dir/1", "1"); err != nil {
+				return err
+			}
+			if err := upload(t, bucket, "dir/2", "2"); err != nil {
+				return err
+			}
+			publisher.published()
+			return bucket.DeleteMany(ctx, []string{"dir/1", "missing"})
+		}, []string{"ObjectDeleted dir/1"}},
+		{"delete prefix", func() error {
+			deleted, err := bucket.DeletePrefix(ctx, "dir/")
+			if err == nil && deleted != 1 {
+				return errors.New("DeletePrefix deleted the wrong number of objects")
+			}
+			return err
+		}, []string{"ObjectDeleted dir/2"}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.write(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := publisher.published(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s published %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEventBucketWithoutOutbox(t *testing.T) {
+	publisher := &recordingPublisher{down: true}
+	bucket := NewEventBucket(NewMemoryBucket(""), publisher)
+
+	err := upload(t, bucket, "a.txt", "content")
+	var publishErr *EventPublishError
+	if !errors.As(err, &publishErr) || len(publishErr.Events) != 1 || publishErr.Events[0].Key != "a.txt" {
+		t.Fatalf("error = %v, want an EventPublishError", err)
+	}
+	if _, err := bucket.Stat(context.Background(), "a.txt"); err != nil {
+		t.Errorf("object not written: %v", err)
+	}
+}
+
+func TestEventBucketOutbox(t *testing.T) {
+	publisher := &recordingPublisher{}
+	outbox := NewMemoryOutbox()
+	bucket := NewEventBucket(NewMemoryBucket(""), publisher, WithOutbox(outbox))
+	relay := NewOutboxRelay(outbox, publisher, OutboxRelayConfig{BatchSize: 1})
+	ctx := context.Background()
+
+	publisher.setDown(true)
+	if err := upload(t, bucket, "a.txt", "1"); err != nil {
+		t.Fatalf("upload with the broker down: %v", err)
+	}
+	if published, err := relay.Flush(ctx); err == nil || published != 0 {
+		t.Fatalf("Flush with the broker down = %d, %v", published, err)
+	}
+
+	// The broker is back, but the first event of a.txt has not been published yet.
+	publisher.setDown(false)
+	if err := bucket.DeleteFile(ctx, "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := upload(t, bucket, "b.txt", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := publisher.published(); strings.Join(got, ",") != "ObjectCreated b.txt" {
+		t.Errorf("published %v, want the events of a.txt kept for the relay", got)
+	}
+
+	pending, err := outbox.Pending(ctx, 10)
+	if err != nil || len(pending) != 2 || pending[0].Attempts != 1 {
+		t.Fatalf("outbox = %+v, %v", pending, err)
+	}
+
+	published, err := relay.Flush(ctx)
+	if err != nil || published != 2 {
+		t.Fatalf("Flush = %d, %v", published, err)
+	}
+	if got := publisher.published(); strings.Join(got, ",") != "ObjectCreated a.txt,ObjectDeleted a.txt" {
+		t.Errorf("relay published %v", got)
+	}
+	if pending, _ := outbox.HasPending(ctx, DefaultEventsTopic, "a.txt"); pending {
+		t.Error("outbox not emptied")
+	}
+
+	if err := upload(t, bucket, "a.txt", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if got := publisher.published(); strings.Join(got, ",") != "ObjectCreated a.txt" {
+		t.Errorf("published %v, want direct publishes once the outbox is empty", got)
+	}
+}
+
+// blockingPublisher blocks until ctx is done.
+type blockingPublisher struct {
+	started chan struct{}
+}
+
+func (p *blockingPublisher) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	close(p.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestOutboxRelayStop(t *testing.T) {
+	outbox := NewMemoryOutbox()
+	if err := outbox.Add(context.Background(), OutboxMessage{ID: "1", Topic: DefaultEventsTopic, Key: "a.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	publisher := &blockingPublisher{started: make(chan struct{})}
+	relay := NewOutboxRelay(outbox, publisher, OutboxRelayConfig{Interval: time.Hour})
+
+	done := make(chan error)
+	go func() { done <- relay.Start() }()
+	<-publisher.started
+	if err := relay.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not interrupt the running publish")
+	}
+	if pending, _ := outbox.HasPending(context.Background(), DefaultEventsTopic, "a.txt"); !pending {
+		t.Error("interrupted message removed from the outbox")
+	}
+}
+
+func TestOutboxRelayFailingMessage(t *testing.T) {
+	ctx := context.Background()
+	outbox := NewMemoryOutbox()
+	for _, message := range []OutboxMessage{
+		{ID: "1", Topic: DefaultEventsTopic, Key: "a.txt", Value: []byte("bad")},
+		{ID: "2", Topic: DefaultEventsTopic, Key: "b.txt", Value: []byte("ok")},
+		{ID: "3", Topic: DefaultEventsTopic, Key: "a.txt", Value: []byte("ok")},
+	} {
+		if err := outbox.Add(ctx, message); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var published []string
+	publisher := PublisherFunc(func(ctx context.Context, topic string, key string, value []byte) error {
+		if string(value) == "bad" {
+			return errors.New("message too large")
+		}
+		published = append(published, key)
+		return nil
+	})
+	relay := NewOutboxRelay(outbox, publisher, OutboxRelayConfig{MaxAttempts: 2})
+
+	// The failing message only holds back the later message of its key.
+	if n, err := relay.Flush(ctx); err == nil || n != 1 {
+		t.Fatalf("first Flush = %d, %v", n, err)
+	}
+	if strings.Join(published, ",") != "b.txt" {
+		t.Errorf("published %v, want b.txt only", published)
+	}
+
+	// The second failure dead-letters it, which releases its key.
+	if n, err := relay.Flush(ctx); err != nil || n != 1 {
+		t.Fatalf("second Flush = %d, %v", n, err)
+	}
+	if strings.Join(published, ",") != "b.txt,a.txt" {
+		t.Errorf("published %v, want a.txt after the dead letter", published)
+	}
+	if dead := outbox.DeadLetters(); len(dead) != 1 || dead[0].ID != "1" || dead[0].Attempts != 1 {
+		t.Errorf("dead letters = %+v", dead)
+	}
+	if pending, _ := outbox.Pending(ctx, 10); len(pending) != 0 {
+		t.Errorf("pending = %+v", pending)
+	}
+}
+
+// slowFailingPublisher fails the first publish once release is closed and records the later ones.
+type slowFailingPublisher struct {
+	recordingPublisher
+	calls   int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *slowFailingPublisher) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	p.mutex.Lock()
+	p.calls++
+	first := p.calls == 1
+	p.mutex.Unlock()
+
+	if first {
+		close(p.started)
+		<-p.release
+		return errors.New("broker unavailable")
+	}
+	return p.recordingPublisher.Publish(ctx, topic, key, value)
+}
+
+func TestEventBucketConcurrentSends(t *testing.T) {
+	publisher := &slowFailingPublisher{started: make(chan struct{}), release: make(chan struct{})}
+	outbox := NewMemoryOutbox()
+	bucket := NewEventBucket(NewMemoryBucket(""), publisher, WithOutbox(outbox))
+	ctx := context.Background()
+
+	first := make(chan error)
+	go func() { first <- upload(t, bucket, "a.txt", "1") }()
+	<-publisher.started
+
+	// The delete must wait for the failed upload event to reach the outbox instead of being
+	// published before it.
+	second := make(chan error)
+	go func() { second <- bucket.DeleteFile(ctx, "a.txt") }()
+	time.Sleep(50 * time.Millisecond)
+	close(publisher.release)
+
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-second; err != nil {
+		t.Fatal(err)
+	}
+	if got := publisher.published(); len(got) != 0 {
+		t.Errorf("published %v before the outbox", got)
+	}
+	pending, err := outbox.Pending(ctx, 10)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("outbox = %+v, %v", pending, err)
+	}
+
+	if _, err := NewOutboxRelay(outbox, publisher, OutboxRelayConfig{}).Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := publisher.published(); strings.Join(got, ",") != "ObjectCreated a.txt,ObjectDeleted a.txt" {
+		t.Errorf("relay published %v", got)
+	}
+}
@@ -0,0 +1,187 @@
+package minio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+const (
+	jpegSOI  = 0xD8
+	jpegEOI  = 0xD9
+	jpegSOS  = 0xDA
+	jpegAPP1 = 0xE1
+	// APP13 holds Photoshop IPTC data, COM free text comments.
+	jpegAPP13 = 0xED
+	jpegCOM   = 0xFE
+
+	exifOrientationTag = 0x0112
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// jpegSegments calls segment for each marker segment before the image data with its marker and
+// bytes, including the marker and length, and returns the offset of the image data.
+func jpegSegments(data []byte, segment func(marker byte, content []byte)) (int, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != jpegSOI {
+		return 0, false
+	}
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 0, false
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// Fill byte.
+			i++
+			continue
+		}
+		if marker == jpegSOS || marker == jpegEOI {
+			return i, true
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 0, false
+		}
+		segment(marker, data[i:i+2+length])
+		i += 2 + length
+	}
+	return 0, false
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG image, from 1 to 8, or 1 when it has none.
+func jpegOrientation(data []byte) int {
+	orientation := 1
+	jpegSegments(data, func(marker byte, content []byte) {
+		payload := content[4:]
+		if marker != jpegAPP1 || !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return
+		}
+		if value, ok := exifOrientation(payload[6:]); ok && value >= 1 && value <= 8 {
+			orientation = value
+		}
+	})
+	return orientation
+}
+
+// exifOrientation reads the orientation tag of the first IFD of a TIFF header.
+func exifOrientation(tiff []byte) (int, bool) {
+	if len(tiff) < 8 {
+		return 0, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8:])), true
+		}
+	}
+	return 0, false
+}
+
+// stripJPEGMetadata removes the EXIF, XMP, IPTC and comment segments of a JPEG image without
+// re-encoding it. ICC profiles and the JFIF and Adobe segments are kept, they affect the colors.
+func stripJPEGMetadata(data []byte) ([]byte, bool) {
+	result := make([]byte, 0, len(data))
+	result = append(result, data[:2]...)
+	start, ok := jpegSegments(data, func(marker byte, content []byte) {
+		if marker == jpegAPP1 || marker == jpegAPP13 || marker == jpegCOM {
+			return
+		}
+		result = append(result, content...)
+	})
+	if !ok {
+		return nil, false
+	}
+	return append(result, data[start:]...), true
+}
+
+// stripPNGMetadata removes the EXIF, text and time chunks of a PNG image.
+func stripPNGMetadata(data []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, false
+	}
+	result := make([]byte, 0, len(data))
+	result = append(result, pngSignature...)
+
+	i := len(pngSignature)
+	for i+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, false
+		}
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			result = append(result, data[i:end]...)
+		}
+		if string(data[i+4:i+8]) == "IEND" {
+			return result, true
+		}
+		i = end
+	}
+	return nil, false
+}
+
+// orient turns an image stored with an EXIF orientation upright.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	width, height := bounds.Dx(), bounds.Dy()
+	if orientation >= 5 {
+		// Orientations 5 to 8 swap the dimensions.
+		width, height = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	w, h := bounds.Dx(), bounds.Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,208 @@
+package minio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(payload)))
+	return append(segment, payload...)
+}
+
+// exifPayload returns an APP1 payload whose first IFD holds the orientation tag.
+func exifPayload(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+	return append([]byte("Exif\x00\x00"), tiff...)
+}
+
+func jpegWith(segments ...[]byte) []byte {
+	data := []byte{0xFF, jpegSOI}
+	for _, segment := range segments {
+		data = append(data, segment...)
+	}
+	return append(data, 0xFF, jpegSOS, 0x00, 0x02, 0xAB, 0xFF, jpegEOI)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no EXIF", jpegWith(jpegSegment(0xE0, []byte("JFIF\x00"))), 1},
+		{"little endian", jpegWith(jpegSegment(jpegAPP1, exifPayload(binary.LittleEndian, 6))), 6},
+		{"big endian", jpegWith(jpegSegment(jpegAPP1, exifPayload(binary.BigEndian, 3))), 3},
+		{"out of range", jpegWith(jpegSegment(jpegAPP1, exifPayload(binary.BigEndian, 9))), 1},
+		{"XMP in APP1", jpegWith(jpegSegment(jpegAPP1, []byte("http://ns.adobe.com/xap/1.0/\x00"))), 1},
+		{"truncated IFD", jpegWith(jpegSegment(jpegAPP1, exifPayload(binary.BigEndian, 6)[:16])), 1},
+		{"bad byte order", jpegWith(jpegSegment(jpegAPP1, append([]byte("Exif\x00\x00XX"), make([]byte, 20)...))), 1},
+		{"truncated segment", jpegWith(jpegSegment(jpegAPP1, exifPayload(binary.BigEndian, 6)))[:12], 1},
+		{"not a JPEG", []byte("\x89PNG\r\n\x1a\n"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	jfif := jpegSegment(0xE0, []byte("JFIF\x00"))
+	icc := jpegSegment(0xE2, []byte("ICC_PROFILE\x00"))
+	exif := jpegSegment(jpegAPP1, exifPayload(binary.BigEndian, 6))
+	iptc := jpegSegment(jpegAPP13, []byte("Photoshop 3.0\x00"))
+	comment := jpegSegment(jpegCOM, []byte("secret"))
+
+	tests := []struct {
+		name   string
+		data   []byte
+		want   []byte
+		wantOK bool
+	}{
+		{
+			name:   "metadata removed",
+			data:   jpegWith(jfif, exif, icc, iptc, comment),
+			want:   jpegWith(jfif, icc),
+			wantOK: true,
+		},
+		{
+			name:   "fill bytes",
+			data:   append([]byte{0xFF, jpegSOI, 0xFF}, jpegWith(comment)[2:]...),
+			want:   jpegWith(),
+			wantOK: true,
+		},
+		{name: "no image data", data: append([]byte{0xFF, jpegSOI}, jfif...)},
+		{name: "bad length", data: []byte{0xFF, jpegSOI, 0xFF, 0xE0, 0x00, 0x01, 0xFF, jpegEOI}},
+		{name: "not a JPEG", data: []byte("GIF89a")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := stripJPEGMetadata(tt.data)
+			if ok != tt.wantOK || !bytes.Equal(got, tt.want) {
+				t.Errorf("stripJPEGMetadata = %x, %t, want %x, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestStripJPEGMetadataDecodes(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := encoded.Bytes()
+	withEXIF := append(append(append([]byte{}, data[:2]...), jpegSegment(jpegAPP1, exifPayload(binary.LittleEndian, 8))...), data[2:]...)
+
+	stripped, ok := stripJPEGMetadata(withEXIF)
+	if !ok {
+		t.Fatal("stripJPEGMetadata failed")
+	}
+	if !bytes.Equal(stripped, data) {
+		t.Error("stripped image differs from the original")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("decode: %v", err)
+	}
+}
+
+func pngChunk(kind string, content []byte) []byte {
+	chunk := make([]byte, 8, 12+len(content))
+	binary.BigEndian.PutUint32(chunk, uint32(len(content)))
+	copy(chunk[4:], kind)
+	// The CRC is not checked by stripPNGMetadata.
+	return append(append(chunk, content...), 0, 0, 0, 0)
+}
+
+func TestStripPNGMetadata(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	data := encoded.Bytes()
+	// IHDR is 25 bytes long after the signature.
+	header := len(pngSignature) + 25
+	withText := append(append(append([]byte{}, data[:header]...), pngChunk("tEXt", []byte("Author\x00me"))...), data[header:]...)
+	withAll := append(append(append([]byte{}, data[:header]...),
+		append(pngChunk("eXIf", []byte("MM\x00\x2a")), pngChunk("tIME", make([]byte, 7))...)...), data[header:]...)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		want   []byte
+		wantOK bool
+	}{
+		{"text", withText, data, true},
+		{"EXIF and time", withAll, data, true},
+		{"nothing to strip", data, data, true},
+		{"missing IEND", data[:len(data)-12], nil, false},
+		{"bad length", append(append([]byte{}, pngSignature...), 0xFF, 0xFF, 0xFF, 0xFF, 'I', 'H', 'D', 'R', 0, 0, 0, 0), nil, false},
+		{"not a PNG", []byte("GIF89a"), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := stripPNGMetadata(tt.data)
+			if ok != tt.wantOK || !bytes.Equal(got, tt.want) {
+				t.Errorf("stripPNGMetadata ok = %t, want %t, %d bytes, want %d", ok, tt.wantOK, len(got), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3x2 image whose top left pixel is red.
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	red := color.RGBA{R: 255, A: 255}
+	img.Set(0, 0, red)
+
+	tests := []struct {
+		orientation   int
+		width, height int
+		redX, redY    int
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+		{9, 3, 2, 0, 0},
+	}
+
+	for _, tt := range tests {
+		got := orient(img, tt.orientation)
+		bounds := got.Bounds()
+		if bounds.Dx() != tt.width || bounds.Dy() != tt.height {
+			t.Errorf("orientation %d: size = %dx%d, want %dx%d", tt.orientation, bounds.Dx(), bounds.Dy(), tt.width, tt.height)
+			continue
+		}
+		if c := color.RGBAModel.Convert(got.At(tt.redX, tt.redY)); c != red {
+			t.Errorf("orientation %d: pixel (%d, %d) = %v, want red", tt.orientation, tt.redX, tt.redY, c)
+		}
+	}
+}
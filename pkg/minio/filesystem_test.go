@@ -0,0 +1,18 @@
+package minio_test
+
+import (
+	"testing"
+
+	pkgMinio "github.com/Melodia-IS2/melodia-go-utils/pkg/minio"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/minio/miniotest"
+)
+
+func TestFileSystemBucketConformance(t *testing.T) {
+	miniotest.RunConformance(t, func(t *testing.T) pkgMinio.MinioBucket {
+		bucket, err := pkgMinio.NewFileSystemBucket(t.TempDir(), "http://localhost/files")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bucket
+	})
+}
@@ -0,0 +1,270 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"maps"
+	"mime/multipart"
+	"path"
+	"strconv"
+	"strings"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+
+	"github.com/minio/minio-go/v7"
+	"golang.org/x/image/draw"
+)
+
+const (
+	defaultMaxImagePixels = 50_000_000
+	defaultJPEGQuality    = 85
+	// originalJPEGQuality is used when a JPEG original has to be re-encoded to apply its orientation.
+	originalJPEGQuality = 95
+)
+
+type ImageVariant struct {
+	// Name is appended to the key of the original, see VariantKey.
+	Name string
+	// Width and Height bound the variant, which keeps the aspect ratio and is never upscaled.
+	// Zero leaves that dimension unbounded.
+	Width  int
+	Height int
+	// Square crops the center of the image to a square of Width pixels.
+	Square bool
+}
+
+// DefaultImageVariants are used by UploadImage when no variants are configured.
+var DefaultImageVariants = []ImageVariant{
+	{Name: "thumb", Width: 150, Height: 150, Square: true},
+	{Name: "300", Width: 300, Height: 300},
+	{Name: "1000", Width: 1000, Height: 1000},
+}
+
+type ImageUploadConfig struct {
+	// Variants defaults to DefaultImageVariants.
+	Variants []ImageVariant
+	// MaxPixels rejects larger images before decoding them. Defaults to 50 megapixels.
+	MaxPixels int
+	// JPEGQuality of the variants of JPEG images. Defaults to 85.
+	JPEGQuality int
+	// UserMetadata is added to the original and its variants.
+	UserMetadata map[string]string
+}
+
+type StoredImage struct {
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// ImageUploadResult is the original image and its variants by name.
+type ImageUploadResult struct {
+	StoredImage
+	Variants map[string]StoredImage `json:"variants"`
+}
+
+// UploadImage uploads a JPEG, PNG or GIF form file under fileName and a resized variant of it per
+// configured variant, all without EXIF and other embedded metadata. The original keeps its format
+// and records its width and height in metadata. JPEG images get JPEG variants and the others PNG ones.
+// If any upload fails, the objects already uploaded are deleted.
+func UploadImage(ctx context.Context, bucket MinioBucket, fileName string, fileHeader *multipart.FileHeader, cfg ImageUploadConfig) (ImageUploadResult, error) {
+	if cfg.Variants == nil {
+		cfg.Variants = DefaultImageVariants
+	}
+	if cfg.MaxPixels == 0 {
+		cfg.MaxPixels = defaultMaxImagePixels
+	}
+	if cfg.JPEGQuality == 0 {
+		cfg.JPEGQuality = defaultJPEGQuality
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return ImageUploadResult{}, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return ImageUploadResult{}, err
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png" && format != "gif") {
+		return ImageUploadResult{}, pkgErrors.NewValidationError("File must be a JPEG, PNG or GIF image")
+	}
+	if config.Width*config.Height > cfg.MaxPixels {
+		return ImageUploadResult{}, pkgErrors.NewValidationError(fmt.Sprintf("Image must not exceed %d pixels", cfg.MaxPixels))
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ImageUploadResult{}, pkgErrors.NewValidationError("Image is corrupted")
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+		img = orient(img, orientation)
+	}
+	original, err := stripImageMetadata(data, format, img, orientation)
+	if err != nil {
+		return ImageUploadResult{}, err
+	}
+
+	var uploaded []UploadedFile
+	upload := func(key string, content []byte, contentType string, bounds image.Rectangle, metadata map[string]string) (StoredImage, error) {
+		metadata = maps.Clone(metadata)
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		maps.Copy(metadata, cfg.UserMetadata)
+		metadata["width"] = strconv.Itoa(bounds.Dx())
+		metadata["height"] = strconv.Itoa(bounds.Dy())
+
+		err := bucket.UploadFile(ctx, key, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: metadata,
+		})
+		if err != nil {
+			return StoredImage{}, err
+		}
+		uploaded = append(uploaded, UploadedFile{Key: key})
+		return StoredImage{
+			Key:         key,
+			URL:         bucket.GetObjectURL(key),
+			Width:       bounds.Dx(),
+			Height:      bounds.Dy(),
+			ContentType: contentType,
+			Size:        int64(len(content)),
+		}, nil
+	}
+
+	result := ImageUploadResult{Variants: make(map[string]StoredImage, len(cfg.Variants))}
+	result.StoredImage, err = upload(fileName, original, "image/"+format, img.Bounds(), map[string]string{
+		"original_filename": fileHeader.Filename,
+	})
+	if err != nil {
+		return ImageUploadResult{}, err
+	}
+
+	for _, variant := range cfg.Variants {
+		resized := resizeImage(img, variant)
+
+		var content bytes.Buffer
+		contentType := "image/png"
+		if format == "jpeg" {
+			contentType = "image/jpeg"
+			err = jpeg.Encode(&content, resized, &jpeg.Options{Quality: cfg.JPEGQuality})
+		} else {
+			err = png.Encode(&content, resized)
+		}
+		if err == nil {
+			result.Variants[variant.Name], err = upload(VariantKey(fileName, variant.Name), content.Bytes(), contentType, resized.Bounds(), map[string]string{
+				"variant": variant.Name,
+			})
+		}
+		if err != nil {
+			cleanup(bucket, uploaded)
+			return ImageUploadResult{}, err
+		}
+	}
+	return result, nil
+}
+
+// VariantKey returns the key of a variant of the image stored under key, e.g. "covers/1_300.jpg"
+// for "covers/1.jpg". Variants of GIF images are PNG images, so their extension becomes ".png".
+func VariantKey(key string, variant string) string {
+	ext := path.Ext(key)
+	if strings.Contains(ext, "/") {
+		ext = ""
+	}
+	base := strings.TrimSuffix(key, ext)
+	if strings.EqualFold(ext, ".gif") {
+		ext = ".png"
+	}
+	return base + "_" + variant + ext
+}
+
+func resizeImage(img image.Image, variant ImageVariant) image.Image {
+	bounds := img.Bounds()
+
+	if variant.Square {
+		side := min(bounds.Dx(), bounds.Dy())
+		x := bounds.Min.X + (bounds.Dx()-side)/2
+		y := bounds.Min.Y + (bounds.Dy()-side)/2
+		size := side
+		if variant.Width > 0 {
+			size = min(side, variant.Width)
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, image.Rect(x, y, x+side, y+side), draw.Src, nil)
+		return dst
+	}
+
+	width, height := fitSize(bounds.Dx(), bounds.Dy(), variant.Width, variant.Height)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width == bounds.Dx() && height == bounds.Dy() {
+		draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+		return dst
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// fitSize scales width and height down to fit maxWidth and maxHeight, keeping the aspect ratio.
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		scale = min(scale, float64(maxHeight)/float64(height))
+	}
+	if scale == 1 {
+		return width, height
+	}
+	return max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))
+}
+
+// stripImageMetadata returns the original without EXIF and text metadata, losslessly when possible.
+// JPEG images with an orientation other than the default are re-encoded upright since stripping
+// EXIF also drops the orientation.
+func stripImageMetadata(data []byte, format string, img image.Image, orientation int) ([]byte, error) {
+	var stripped []byte
+	ok := false
+	switch format {
+	case "jpeg":
+		if orientation <= 1 {
+			stripped, ok = stripJPEGMetadata(data)
+		}
+	case "png":
+		stripped, ok = stripPNGMetadata(data)
+	case "gif":
+		// GIF has no EXIF, comments are kept.
+		return data, nil
+	}
+	if ok {
+		return stripped, nil
+	}
+
+	var content bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&content, img, &jpeg.Options{Quality: originalJPEGQuality})
+	case "png":
+		err = png.Encode(&content, img)
+	default:
+		err = gif.Encode(&content, img, nil)
+	}
+	return content.Bytes(), err
+}
@@ -12,6 +12,8 @@ type MinioInstanceCfg struct {
 	AccessKey string
 	SecretKey string
 	UseSSL    bool
+	// Region skips the bucket location lookup, e.g. when the client is only used to presign URLs.
+	Region string
 }
 
 func NewMinioInstance(cfg *MinioInstanceCfg) (*minio.Client, error) {
@@ -22,5 +24,6 @@ func NewMinioInstance(cfg *MinioInstanceCfg) (*minio.Client, error) {
 	return minio.New(cfg.Endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
 		Secure: cfg.UseSSL,
+		Region: cfg.Region,
 	})
 }
@@ -3,16 +3,23 @@ package minio
 import (
 	"context"
 	"io"
+	"iter"
 	"mime/multipart"
+	"time"
 
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
 	"github.com/minio/minio-go/v7"
 )
 
+// ObjectData is returned by DownloadFile. Reader is also an io.ReadSeeker when the
+// implementation supports seeking, which ServeObject uses for range requests.
 type ObjectData struct {
 	Reader       io.Reader
 	ContentType  string
 	FileName     string
 	Size         int64
+	ETag         string
+	LastModified time.Time
 	UserMetadata map[string]string
 }
 
@@ -23,4 +30,20 @@ type MinioBucket interface {
 
 	UploadFileHeader(ctx context.Context, fileName string, fileHeader *multipart.FileHeader, opts minio.PutObjectOptions) error
 	GetObjectURL(objectName string) string
+
+	Stat(ctx context.Context, objectName string) (ObjectInfo, error)
+	Exists(ctx context.Context, objectName string) (bool, error)
+	List(ctx context.Context, prefix string) iter.Seq2[ObjectInfo, error]
+	ListPage(ctx context.Context, prefix string, startAfter string, limit int) (ObjectPage, error)
+	Copy(ctx context.Context, src string, dst string) error
+	Move(ctx context.Context, src string, dst string) error
+	DeleteMany(ctx context.Context, objectNames []string) error
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+
+	PresignGet(ctx context.Context, objectName string, opts PresignGetOptions) (PresignedURL, error)
+	PresignPut(ctx context.Context, objectName string, opts PresignPutOptions) (PresignedURL, error)
+	PresignPostPolicy(ctx context.Context, opts PresignPostOptions) (PresignedPost, error)
+
+	// Store returns the provider neutral store of the bucket, for code that should not depend on minio-go.
+	Store() objectstore.Store
 }
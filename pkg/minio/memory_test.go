@@ -0,0 +1,22 @@
+package minio_test
+
+import (
+	"testing"
+
+	pkgMinio "github.com/Melodia-IS2/melodia-go-utils/pkg/minio"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/minio/miniotest"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
+)
+
+func TestMemoryBucketConformance(t *testing.T) {
+	miniotest.RunConformance(t, func(t *testing.T) pkgMinio.MinioBucket {
+		return pkgMinio.NewMemoryBucket("http://localhost/files")
+	})
+}
+
+func TestContentAddressedBucketConformance(t *testing.T) {
+	miniotest.RunConformance(t, func(t *testing.T) pkgMinio.MinioBucket {
+		store := objectstore.NewContentAddressed(objectstore.NewMemory("http://localhost/files"), objectstore.WithSpoolDir(t.TempDir()))
+		return pkgMinio.NewStoreBucket(store)
+	})
+}
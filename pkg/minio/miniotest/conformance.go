@@ -0,0 +1,427 @@
+// Package miniotest checks that MinioBucket implementations behave the same way.
+package miniotest
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	pkgMinio "github.com/Melodia-IS2/melodia-go-utils/pkg/minio"
+	"github.com/minio/minio-go/v7"
+)
+
+// RunConformance runs the shared MinioBucket behaviour against buckets returned by newBucket,
+// which must return an empty bucket on every call.
+func RunConformance(t *testing.T, newBucket func(t *testing.T) pkgMinio.MinioBucket) {
+	t.Run("UploadDownload", func(t *testing.T) {
+		bucket := newBucket(t)
+		ctx := context.Background()
+		content := []byte("some audio")
+
+		upload(t, bucket, "songs/a.mp3", content, minio.PutObjectOptions{
+			ContentType:  "audio/mpeg",
+			UserMetadata: map[string]string{"original_filename": "a.mp3"},
+		})
+
+		object, err := bucket.DownloadFile(ctx, "songs/a.mp3")
+		if err != nil {
+			t.Fatalf("DownloadFile: %v", err)
+		}
+		got := readAll(t, object.Reader)
+		if !bytes.Equal(got, content) {
+			t.Errorf("content = %q, want %q", got, content)
+		}
+		if object.ContentType != "audio/mpeg" {
+			t.Errorf("ContentType = %q, want audio/mpeg", object.ContentType)
+		}
+		if object.FileName != "a.mp3" {
+			t.Errorf("FileName = %q, want a.mp3", object.FileName)
+		}
+		if object.Size != int64(len(content)) {
+			t.Errorf("Size = %d, want %d", object.Size, len(content))
+		}
+		if object.ETag != md5Hex(content) {
+			t.Errorf("ETag = %q, want %q", object.ETag, md5Hex(content))
+		}
+		if object.LastModified.IsZero() {
+			t.Error("LastModified is zero")
+		}
+	})
+
+	t.Run("UploadUnknownSize", func(t *testing.T) {
+		bucket := newBucket(t)
+		upload(t, bucket, "a", []byte("content"), minio.PutObjectOptions{})
+
+		info, err := bucket.Stat(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		if info.Size != 7 {
+			t.Errorf("Size = %d, want 7", info.Size)
+		}
+	})
+
+	t.Run("UploadShortReader", func(t *testing.T) {
+		bucket := newBucket(t)
+		ctx := context.Background()
+
+		err := bucket.UploadFile(ctx, "a", bytes.NewReader([]byte("abc")), 10, minio.PutObjectOptions{})
+		if err == nil {
+			t.Fatal("UploadFile with a short reader succeeded")
+		}
+		if exists(t, bucket, "a") {
+			t.Error("a failed upload left an object")
+		}
+	})
+
+	t.Run("UploadFileHeader", func(t *testing.T) {
+		bucket := newBucket(t)
+		header := fileHeader(t, "cover.png", "image/png", []byte("png data"))
+
+		if err := bucket.UploadFileHeader(context.Background(), "covers/1", header, minio.PutObjectOptions{}); err != nil {
+			t.Fatalf("UploadFileHeader: %v", err)
+		}
+		object, err := bucket.DownloadFile(context.Background(), "covers/1")
+		if err != nil {
+			t.Fatalf("DownloadFile: %v", err)
+		}
+		readAll(t, object.Reader)
+		if object.ContentType != "image/png" {
+			t.Errorf("ContentType = %q, want image/png", object.ContentType)
+		}
+		if object.FileName != "cover.png" {
+			t.Errorf("FileName = %q, want cover.png", object.FileName)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		bucket := newBucket(t)
+		ctx := context.Background()
+
+		if _, err := bucket.DownloadFile(ctx, "missing"); !pkgMinio.IsNotFound(err) {
+			t.Errorf("DownloadFile error = %v, want not found", err)
+		}
+		if _, err := bucket.Stat(ctx, "missing"); !pkgMinio.IsNotFound(err) {
+			t.Errorf("Stat error = %v, want not found", err)
+		}
+		if exists(t, bucket, "missing") {
+			t.Error("Exists = true for a missing object")
+		}
+		if err := bucket.DeleteFile(ctx, "missing"); err != nil {
+			t.Errorf("DeleteFile of a missing object: %v", err)
+		}
+		if err := bucket.Copy(ctx, "missing", "copy"); !pkgMinio.IsNotFound(err) {
+			t.Errorf("Copy error = %v, want not found", err)
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		bucket := newBucket(t)
+		upload(t, bucket, "a", []byte("first"), minio.PutObjectOptions{
+			ContentType:  "text/plain",
+			UserMetadata: map[string]string{"original_filename": "first.txt"},
+		})
+		first, err := bucket.Stat(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		upload(t, bucket, "a", []byte("second version"), minio.PutObjectOptions{ContentType: "text/csv"})
+
+		object, err := bucket.DownloadFile(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("DownloadFile: %v", err)
+		}
+		if got := readAll(t, object.Reader); string(got) != "second version" {
+			t.Errorf("content = %q, want %q", got, "second version")
+		}
+		if object.ContentType != "text/csv" {
+			t.Errorf("ContentType = %q, want text/csv", object.ContentType)
+		}
+		if object.FileName != "" {
+			t.Errorf("FileName = %q, want the metadata of the first upload to be gone", object.FileName)
+		}
+		if object.ETag == first.ETag {
+			t.Errorf("ETag = %q did not change with the content", object.ETag)
+		}
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		bucket := newBucket(t)
+		upload(t, bucket, "a", []byte("a"), minio.PutObjectOptions{
+			UserMetadata: map[string]string{"Original_Filename": "a.txt", "owner": "42"},
+		})
+
+		info, err := bucket.Stat(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		if info.UserMetadata["original_filename"] != "a.txt" || info.UserMetadata["owner"] != "42" {
+			t.Errorf("UserMetadata = %v, want lowercase keys", info.UserMetadata)
+		}
+		if info.ContentType == "" {
+			t.Error("ContentType is empty, want a default")
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		bucket := newBucket(t)
+		for _, name := range []string{"songs/b", "covers/a", "songs/a", "songs/c/d"} {
+			upload(t, bucket, name, []byte(name), minio.PutObjectOptions{})
+		}
+
+		var keys []string
+		for object, err := range bucket.List(context.Background(), "songs/") {
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if object.Size != int64(len(object.Key)) {
+				t.Errorf("%s Size = %d, want %d", object.Key, object.Size, len(object.Key))
+			}
+			keys = append(keys, object.Key)
+		}
+		if want := []string{"songs/a", "songs/b", "songs/c/d"}; !slices.Equal(keys, want) {
+			t.Errorf("keys = %v, want %v", keys, want)
+		}
+
+		count := 0
+		for range bucket.List(context.Background(), "") {
+			count++
+			break
+		}
+		if count != 1 {
+			t.Errorf("List kept yielding after break")
+		}
+	})
+
+	t.Run("ListPage", func(t *testing.T) {
+		bucket := newBucket(t)
+		for _, name := range []string{"p/1", "p/2", "p/3", "q/1"} {
+			upload(t, bucket, name, []byte(name), minio.PutObjectOptions{})
+		}
+		ctx := context.Background()
+
+		page, err := bucket.ListPage(ctx, "p/", "", 2)
+		if err != nil {
+			t.Fatalf("ListPage: %v", err)
+		}
+		if got := keysOf(page); !slices.Equal(got, []string{"p/1", "p/2"}) || !page.HasMore || page.NextStartAfter != "p/2" {
+			t.Errorf("first page = %v, HasMore %t, NextStartAfter %q", got, page.HasMore, page.NextStartAfter)
+		}
+
+		page, err = bucket.ListPage(ctx, "p/", page.NextStartAfter, 2)
+		if err != nil {
+			t.Fatalf("ListPage: %v", err)
+		}
+		if got := keysOf(page); !slices.Equal(got, []string{"p/3"}) || page.HasMore {
+			t.Errorf("second page = %v, HasMore %t", got, page.HasMore)
+		}
+
+		if _, err := bucket.ListPage(ctx, "p/", "", 0); err == nil {
+			t.Error("ListPage with limit 0 succeeded")
+		}
+	})
+
+	t.Run("CopyMove", func(t *testing.T) {
+		bucket := newBucket(t)
+		ctx := context.Background()
+		upload(t, bucket, "src", []byte("content"), minio.PutObjectOptions{
+			ContentType:  "text/plain",
+			UserMetadata: map[string]string{"original_filename": "src.txt"},
+		})
+
+		if err := bucket.Copy(ctx, "src", "copy"); err != nil {
+			t.Fatalf("Copy: %v", err)
+		}
+		info, err := bucket.Stat(ctx, "copy")
+		if err != nil {
+			t.Fatalf("Stat copy: %v", err)
+		}
+		if info.ContentType != "text/plain" || info.UserMetadata["original_filename"] != "src.txt" {
+			t.Errorf("copy = %+v, want the source content type and metadata", info)
+		}
+		if !exists(t, bucket, "src") {
+			t.Error("Copy removed the source")
+		}
+
+		if err := bucket.Move(ctx, "src", "moved"); err != nil {
+			t.Fatalf("Move: %v", err)
+		}
+		if exists(t, bucket, "src") {
+			t.Error("Move kept the source")
+		}
+		object, err := bucket.DownloadFile(ctx, "moved")
+		if err != nil {
+			t.Fatalf("DownloadFile moved: %v", err)
+		}
+		if got := readAll(t, object.Reader); string(got) != "content" {
+			t.Errorf("moved content = %q, want content", got)
+		}
+	})
+
+	t.Run("DeleteMany", func(t *testing.T) {
+		bucket := newBucket(t)
+		for _, name := range []string{"a", "b", "c"} {
+			upload(t, bucket, name, []byte(name), minio.PutObjectOptions{})
+		}
+
+		if err := bucket.DeleteMany(context.Background(), []string{"a", "b", "missing"}); err != nil {
+			t.Fatalf("DeleteMany: %v", err)
+		}
+		if exists(t, bucket, "a") || exists(t, bucket, "b") || !exists(t, bucket, "c") {
+			t.Error("DeleteMany deleted the wrong objects")
+		}
+	})
+
+	t.Run("DeletePrefix", func(t *testing.T) {
+		bucket := newBucket(t)
+		for _, name := range []string{"album/1/a", "album/1/b", "album/10/a", "album/2/a"} {
+			upload(t, bucket, name, []byte(name), minio.PutObjectOptions{})
+		}
+		ctx := context.Background()
+
+		count, err := bucket.DeletePrefix(ctx, "album/1/")
+		if err != nil {
+			t.Fatalf("DeletePrefix: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("count = %d, want 2", count)
+		}
+		if !exists(t, bucket, "album/10/a") || !exists(t, bucket, "album/2/a") {
+			t.Error("DeletePrefix deleted objects outside the prefix")
+		}
+		if _, err := bucket.DeletePrefix(ctx, ""); err == nil {
+			t.Error("DeletePrefix with an empty prefix succeeded")
+		}
+	})
+
+	t.Run("GetObjectURL", func(t *testing.T) {
+		bucket := newBucket(t)
+		if url := bucket.GetObjectURL("a/b.png"); url == "" {
+			t.Error("GetObjectURL is empty")
+		}
+		if url := bucket.GetObjectURL(""); url != "" {
+			t.Errorf("GetObjectURL(\"\") = %q, want empty", url)
+		}
+	})
+
+	t.Run("Presign", func(t *testing.T) {
+		bucket := newBucket(t)
+		ctx := context.Background()
+
+		presigned, err := bucket.PresignGet(ctx, "a", pkgMinio.PresignGetOptions{})
+		if errors.Is(err, pkgMinio.ErrPresignUnsupported) {
+			t.Skip("the bucket cannot presign URLs")
+		}
+		if err != nil {
+			t.Fatalf("PresignGet: %v", err)
+		}
+		if presigned.URL == "" || presigned.Method != "GET" || presigned.ExpiresAt.IsZero() {
+			t.Errorf("PresignGet = %+v", presigned)
+		}
+		if _, err := bucket.PresignGet(ctx, "a", pkgMinio.PresignGetOptions{Expiry: 8 * 24 * time.Hour}); err == nil {
+			t.Error("PresignGet with an expiry over 7 days succeeded")
+		}
+		if _, err := bucket.PresignPostPolicy(ctx, pkgMinio.PresignPostOptions{}); err == nil {
+			t.Error("PresignPostPolicy without a key succeeded")
+		}
+	})
+
+	t.Run("Serve", func(t *testing.T) {
+		bucket := newBucket(t)
+		upload(t, bucket, "a", []byte("0123456789"), minio.PutObjectOptions{ContentType: "text/plain"})
+
+		request := httptest.NewRequest("GET", "/a", nil)
+		request.Header.Set("Range", "bytes=2-4")
+		recorder := httptest.NewRecorder()
+		if err := pkgMinio.ServeObject(recorder, request, bucket, "a"); err != nil {
+			t.Fatalf("ServeObject: %v", err)
+		}
+		if recorder.Code != 206 || recorder.Body.String() != "234" {
+			t.Errorf("range response = %d %q, want 206 \"234\"", recorder.Code, recorder.Body.String())
+		}
+	})
+}
+
+func upload(t *testing.T, bucket pkgMinio.MinioBucket, name string, content []byte, opts minio.PutObjectOptions) {
+	t.Helper()
+	size := int64(len(content))
+	if opts.ContentType == "" && opts.UserMetadata == nil {
+		// Also exercise unknown sizes.
+		size = -1
+	}
+	if err := bucket.UploadFile(context.Background(), name, bytes.NewReader(content), size, opts); err != nil {
+		t.Fatalf("UploadFile %s: %v", name, err)
+	}
+}
+
+func exists(t *testing.T, bucket pkgMinio.MinioBucket, name string) bool {
+	t.Helper()
+	ok, err := bucket.Exists(context.Background(), name)
+	if err != nil {
+		t.Fatalf("Exists %s: %v", name, err)
+	}
+	return ok
+}
+
+func readAll(t *testing.T, reader io.Reader) []byte {
+	t.Helper()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return content
+}
+
+func fileHeader(t *testing.T, fileName string, contentType string, content []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreatePart(map[string][]string{
+		"Content-Disposition": {`form-data; name="file"; filename="` + fileName + `"`},
+		"Content-Type":        {contentType},
+	})
+	if err == nil {
+		_, err = part.Write(content)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	files := form.File["file"]
+	if len(files) != 1 {
+		t.Fatal("multipart: file not found")
+	}
+	return files[0]
+}
+
+func keysOf(page pkgMinio.ObjectPage) []string {
+	keys := make([]string, len(page.Objects))
+	for i, object := range page.Objects {
+		keys[i] = object.Key
+	}
+	return keys
+}
+
+func md5Hex(content []byte) string {
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package minio
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+	// ContentType and UserMetadata may be empty in listings.
+	UserMetadata map[string]string
+}
+
+// ObjectPage is a page of a listing. Pass NextStartAfter as startAfter to get the next one.
+type ObjectPage struct {
+	Objects        []ObjectInfo
+	NextStartAfter string
+	HasMore        bool
+}
+
+// BatchDeleteError holds the objects DeleteMany or DeletePrefix could not delete, by object name.
+type BatchDeleteError struct {
+	Errors map[string]error
+}
+
+func (e *BatchDeleteError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > 3 {
+		names = append(names[:3], "...")
+	}
+	return fmt.Sprintf("failed to delete %d objects: %s", len(e.Errors), strings.Join(names, ", "))
+}
+
+func collectPage(objects iter.Seq2[ObjectInfo, error], limit int) (ObjectPage, error) {
+	if limit <= 0 {
+		return ObjectPage{}, fmt.Errorf("limit must be positive")
+	}
+
+	var page ObjectPage
+	for object, err := range objects {
+		if err != nil {
+			return ObjectPage{}, err
+		}
+		if len(page.Objects) == limit {
+			page.HasMore = true
+			break
+		}
+		page.Objects = append(page.Objects, object)
+	}
+	if n := len(page.Objects); n > 0 {
+		page.NextStartAfter = page.Objects[n-1].Key
+	}
+	return page, nil
+}
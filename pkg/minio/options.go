@@ -0,0 +1,99 @@
+package minio
+
+import (
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/sse"
+)
+
+type PolicyMismatchAction int
+
+const (
+	// PolicyMismatchWarn logs a warning through logger.Record and keeps the existing policy.
+	PolicyMismatchWarn PolicyMismatchAction = iota
+	// PolicyMismatchReconcile replaces the existing policy with the declared one.
+	PolicyMismatchReconcile
+	// PolicyMismatchFail makes NewMinioBucket return an error.
+	PolicyMismatchFail
+)
+
+type bucketConfig struct {
+	presignClient *minio.Client
+	access        Access
+	onMismatch    PolicyMismatchAction
+	versioning    bool
+	objectLock    bool
+	retentionMode minio.RetentionMode
+	retentionDays uint
+	encryption    *sse.Configuration
+
+	contentAddressed bool
+	casOptions       []objectstore.ContentAddressedOption
+}
+
+type BucketOption func(cfg *bucketConfig)
+
+// WithPresignClient signs presigned URLs with client instead of the bucket client. Use a client
+// created with the public endpoint when the bucket client uses an internal one, since the host is
+// part of the signature. Setting its Region avoids a bucket location request on every signature.
+func WithPresignClient(client *minio.Client) BucketOption {
+	return func(cfg *bucketConfig) {
+		cfg.presignClient = client
+	}
+}
+
+// WithAccess declares the bucket policy. Defaults to PublicRead().
+func WithAccess(access Access) BucketOption {
+	return func(cfg *bucketConfig) {
+		cfg.access = access
+	}
+}
+
+// WithPolicyMismatch sets what happens when an existing bucket has a different policy than the declared one.
+func WithPolicyMismatch(action PolicyMismatchAction) BucketOption {
+	return func(cfg *bucketConfig) {
+		cfg.onMismatch = action
+	}
+}
+
+// WithVersioning enables versioning when the bucket is created.
+func WithVersioning() BucketOption {
+	return func(cfg *bucketConfig) {
+		cfg.versioning = true
+	}
+}
+
+// WithObjectLock creates the bucket with object lock and a default retention of days, e.g. for invoices.
+// Object lock can only be enabled at creation and implies versioning.
+func WithObjectLock(mode minio.RetentionMode, days uint) BucketOption {
+	return func(cfg *bucketConfig) {
+		cfg.objectLock = true
+		cfg.retentionMode = mode
+		cfg.retentionDays = days
+	}
+}
+
+// WithEncryption sets SSE-S3 default encryption when the bucket is created.
+func WithEncryption() BucketOption {
+	return func(cfg *bucketConfig) {
+		cfg.encryption = sse.NewConfigurationSSES3()
+	}
+}
+
+// WithKMSEncryption sets SSE-KMS default encryption with keyID when the bucket is created.
+func WithKMSEncryption(keyID string) BucketOption {
+	return func(cfg *bucketConfig) {
+		cfg.encryption = sse.NewConfigurationSSEKMS(keyID)
+	}
+}
+
+// WithContentAddressed stores every distinct content once through an objectstore.ContentAddressedStore.
+// Presigned uploads are not supported since content must be hashed before it is stored, and uploads
+// with options such as ServerSideEncryption fail with ErrUnsupportedOption. Run Prune on the store
+// returned by Store() to delete the content no object references anymore.
+func WithContentAddressed(opts ...objectstore.ContentAddressedOption) BucketOption {
+	return func(cfg *bucketConfig) {
+		cfg.contentAddressed = true
+		cfg.casOptions = opts
+	}
+}
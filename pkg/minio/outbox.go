@@ -0,0 +1,245 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/logger"
+)
+
+// OutboxMessage is a message waiting to be published.
+type OutboxMessage struct {
+	ID        string
+	Topic     string
+	Key       string
+	Value     []byte
+	CreatedAt time.Time
+	Attempts  int
+}
+
+// Outbox stores the messages that could not be published.
+type Outbox interface {
+	Add(ctx context.Context, message OutboxMessage) error
+	// Pending returns up to limit messages, oldest first.
+	Pending(ctx context.Context, limit int) ([]OutboxMessage, error)
+	// HasPending reports whether a message with key is waiting to be published to topic.
+	HasPending(ctx context.Context, topic string, key string) (bool, error)
+	// Done removes a published message.
+	Done(ctx context.Context, id string) error
+	// Failed records a failed publish attempt.
+	Failed(ctx context.Context, id string) error
+	// DeadLetter keeps a message that cannot be published out of Pending and HasPending, for
+	// someone to inspect it.
+	DeadLetter(ctx context.Context, id string) error
+}
+
+// MemoryOutbox keeps the messages in memory, so they are lost when the process exits. Use it only
+// for development and tests.
+type MemoryOutbox struct {
+	mutex    sync.Mutex
+	messages []OutboxMessage
+	dead     []OutboxMessage
+}
+
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{}
+}
+
+func (o *MemoryOutbox) Add(ctx context.Context, message OutboxMessage) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.messages = append(o.messages, message)
+	return nil
+}
+
+func (o *MemoryOutbox) Pending(ctx context.Context, limit int) ([]OutboxMessage, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return slices.Clone(o.messages[:min(limit, len(o.messages))]), nil
+}
+
+func (o *MemoryOutbox) HasPending(ctx context.Context, topic string, key string) (bool, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return slices.ContainsFunc(o.messages, func(message OutboxMessage) bool {
+		return message.Topic == topic && message.Key == key
+	}), nil
+}
+
+func (o *MemoryOutbox) Done(ctx context.Context, id string) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.messages = slices.DeleteFunc(o.messages, func(message OutboxMessage) bool {
+		return message.ID == id
+	})
+	return nil
+}
+
+func (o *MemoryOutbox) Failed(ctx context.Context, id string) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for i := range o.messages {
+		if o.messages[i].ID == id {
+			o.messages[i].Attempts++
+		}
+	}
+	return nil
+}
+
+func (o *MemoryOutbox) DeadLetter(ctx context.Context, id string) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.messages = slices.DeleteFunc(o.messages, func(message OutboxMessage) bool {
+		if message.ID == id {
+			o.dead = append(o.dead, message)
+			return true
+		}
+		return false
+	})
+	return nil
+}
+
+// DeadLetters returns the dead-lettered messages.
+func (o *MemoryOutbox) DeadLetters() []OutboxMessage {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return slices.Clone(o.dead)
+}
+
+type OutboxRelayConfig struct {
+	// Interval between two polls of the outbox. Defaults to 5 seconds.
+	Interval time.Duration
+	// BatchSize is the number of messages read from the outbox at once. A batch made only of failing
+	// messages ends the poll, so keep it above the number of keys expected to fail together.
+	// Defaults to 100.
+	BatchSize int
+	// MaxAttempts is the number of failed publishes after which a message is dead-lettered.
+	// Failures while the broker is down count too, so keep MaxAttempts * Interval above the
+	// outages to ride out. Defaults to 100.
+	MaxAttempts int
+}
+
+// OutboxRelay publishes the messages of an outbox until they are accepted by the broker. It is an
+// app.Worker, register it with the builder. Messages are published at least once and in order per
+// key: after a failure the later messages with the same topic and key wait for the next poll, the
+// other keys go on. A message that fails MaxAttempts times is dead-lettered and logged, so it does
+// not block its key forever.
+type OutboxRelay struct {
+	outbox    Outbox
+	publisher Publisher
+	cfg       OutboxRelayConfig
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+func NewOutboxRelay(outbox Outbox, publisher Publisher, cfg OutboxRelayConfig) *OutboxRelay {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 100
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OutboxRelay{
+		outbox:    outbox,
+		publisher: publisher,
+		cfg:       cfg,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start polls the outbox until Stop is called.
+func (r *OutboxRelay) Start() error {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Flush(r.ctx); err != nil && r.ctx.Err() == nil {
+			logger.Record(r.ctx, logger.Warn, logger.LayerApp, fmt.Sprintf("outbox relay: %v", err), nil)
+		}
+		select {
+		case <-r.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop cancels the running poll. A message whose publish is interrupted stays in the outbox and
+// may be published again by the next relay.
+func (r *OutboxRelay) Stop() error {
+	r.cancel()
+	return nil
+}
+
+// Flush publishes the pending messages and returns how many were published. The errors of the
+// failed publishes are joined in the returned error.
+func (r *OutboxRelay) Flush(ctx context.Context) (int, error) {
+	published := 0
+	var errs []error
+	// Keys with a failed message, their later messages wait for the next poll.
+	blocked := map[outboxKey]bool{}
+	for {
+		messages, err := r.outbox.Pending(ctx, r.cfg.BatchSize)
+		if err != nil {
+			return published, errors.Join(append(errs, err)...)
+		}
+		removed := 0
+		for _, message := range messages {
+			key := outboxKey{topic: message.Topic, key: message.Key}
+			if blocked[key] {
+				continue
+			}
+			publishErr := r.publisher.Publish(ctx, message.Topic, message.Key, message.Value)
+			if publishErr == nil {
+				if err := r.outbox.Done(ctx, message.ID); err != nil {
+					return published, errors.Join(append(errs, err)...)
+				}
+				published++
+				removed++
+				continue
+			}
+			if ctx.Err() != nil {
+				// Interrupted by Stop, not a failure of the message.
+				return published, ctx.Err()
+			}
+			if message.Attempts+1 >= r.cfg.MaxAttempts {
+				if err := r.outbox.DeadLetter(ctx, message.ID); err != nil {
+					return published, errors.Join(append(errs, err)...)
+				}
+				logger.Record(ctx, logger.Error, logger.LayerApp, fmt.Sprintf("outbox relay: message %s to %s dead-lettered after %d attempts: %v", message.ID, message.Topic, message.Attempts+1, publishErr), nil)
+				removed++
+				continue
+			}
+			if err := r.outbox.Failed(ctx, message.ID); err != nil {
+				return published, errors.Join(append(errs, err)...)
+			}
+			blocked[key] = true
+			errs = append(errs, fmt.Errorf("publishing message %s: %w", message.ID, publishErr))
+		}
+		// Without removed messages the next batch would be the same one.
+		if len(messages) < r.cfg.BatchSize || removed == 0 {
+			return published, errors.Join(errs...)
+		}
+	}
+}
+
+type outboxKey struct {
+	topic string
+	key   string
+}
@@ -0,0 +1,127 @@
+package minio
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/database"
+)
+
+// MySQLOutboxSchema creates the table used by MySQLOutbox. %s is replaced with the table name.
+const MySQLOutboxSchema = `CREATE TABLE IF NOT EXISTS %s (
+	seq BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	id VARCHAR(64) NOT NULL UNIQUE,
+	topic VARCHAR(255) NOT NULL,
+	message_key VARCHAR(1024) NOT NULL,
+	value LONGBLOB NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	created_at DATETIME(6) NOT NULL,
+	dead_at DATETIME(6) NULL
+)`
+
+// execer is implemented by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// MySQLOutbox needs a connection opened with parseTime=true. Dead-lettered messages stay in the
+// table with dead_at set.
+type MySQLOutbox struct {
+	db    *sql.DB
+	table string
+}
+
+func NewMySQLOutbox(db *sql.DB, table string) (*MySQLOutbox, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if table == "" {
+		table = "object_events_outbox"
+	}
+	return &MySQLOutbox{db: db, table: table}, nil
+}
+
+func (o *MySQLOutbox) CreateTable(ctx context.Context) error {
+	_, err := o.db.ExecContext(ctx, fmt.Sprintf(MySQLOutboxSchema, o.table))
+	return err
+}
+
+func (o *MySQLOutbox) Add(ctx context.Context, message OutboxMessage) error {
+	return o.add(ctx, o.db, message)
+}
+
+// AddTx stores message in tx, so it is only published if tx commits. Use it to publish events
+// together with the database changes that reference the object.
+func (o *MySQLOutbox) AddTx(ctx context.Context, tx *sql.Tx, message OutboxMessage) error {
+	return o.add(ctx, tx, message)
+}
+
+func (o *MySQLOutbox) add(ctx context.Context, exec execer, message OutboxMessage) error {
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+	_, err := exec.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (id, topic, message_key, value, attempts, created_at) VALUES (?, ?, ?, ?, ?, ?)", o.table),
+		message.ID, message.Topic, message.Key, message.Value, message.Attempts, message.CreatedAt.UTC(),
+	)
+	if err == nil {
+		return nil
+	}
+	if sqlErr := database.HandleSqlError(err); sqlErr.ErrorType == database.SqlErrorTypeConflict {
+		// Already stored by a previous attempt.
+		return nil
+	}
+	return err
+}
+
+func (o *MySQLOutbox) Pending(ctx context.Context, limit int) ([]OutboxMessage, error) {
+	rows, err := o.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT id, topic, message_key, value, attempts, created_at FROM %s WHERE dead_at IS NULL ORDER BY seq LIMIT ?", o.table),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []OutboxMessage
+	for rows.Next() {
+		var message OutboxMessage
+		if err := rows.Scan(&message.ID, &message.Topic, &message.Key, &message.Value, &message.Attempts, &message.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// HasPending scans the table, which is small while the broker is reachable.
+func (o *MySQLOutbox) HasPending(ctx context.Context, topic string, key string) (bool, error) {
+	var found int
+	err := o.db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT 1 FROM %s WHERE topic = ? AND message_key = ? AND dead_at IS NULL LIMIT 1", o.table),
+		topic, key,
+	).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (o *MySQLOutbox) Done(ctx context.Context, id string) error {
+	_, err := o.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = ?", o.table), id)
+	return err
+}
+
+func (o *MySQLOutbox) Failed(ctx context.Context, id string) error {
+	_, err := o.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET attempts = attempts + 1 WHERE id = ?", o.table), id)
+	return err
+}
+
+func (o *MySQLOutbox) DeadLetter(ctx context.Context, id string) error {
+	_, err := o.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET dead_at = ? WHERE id = ?", o.table), time.Now().UTC(), id)
+	return err
+}
@@ -0,0 +1,151 @@
+package minio
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Access is the policy of a bucket: Private, PublicRead, PublicReadPrefixes or CustomPolicy.
+type Access struct {
+	name     string
+	prefixes []string
+	policy   string
+}
+
+// Private buckets have no policy, objects are only reachable with credentials or presigned URLs.
+func Private() Access {
+	return Access{name: "private"}
+}
+
+// PublicRead lets anyone download every object.
+func PublicRead() Access {
+	return Access{name: "public-read", prefixes: []string{""}}
+}
+
+// PublicReadPrefixes lets anyone download the objects under prefixes, e.g. "covers/".
+func PublicReadPrefixes(prefixes ...string) Access {
+	return Access{name: "public-read-prefixes", prefixes: prefixes}
+}
+
+// CustomPolicy applies a policy document as is.
+func CustomPolicy(policy string) Access {
+	return Access{name: "custom", policy: policy}
+}
+
+func (a Access) String() string {
+	return a.name
+}
+
+// Policy returns the policy document for bucketName, or "" when the bucket has no policy.
+func (a Access) Policy(bucketName string) (string, error) {
+	if a.name == "custom" {
+		if !json.Valid([]byte(a.policy)) {
+			return "", fmt.Errorf("invalid custom policy for bucket %s", bucketName)
+		}
+		return a.policy, nil
+	}
+	if len(a.prefixes) == 0 {
+		return "", nil
+	}
+
+	resources := make([]string, len(a.prefixes))
+	for i, prefix := range a.prefixes {
+		resources[i] = fmt.Sprintf("arn:aws:s3:::%s/%s*", bucketName, strings.TrimPrefix(prefix, "/"))
+	}
+	policy, err := json.Marshal(policyDocument{
+		Version: "2012-10-17",
+		Statement: []policyStatement{{
+			Effect:    "Allow",
+			Principal: map[string]any{"AWS": []string{"*"}},
+			Action:    []string{"s3:GetObject"},
+			Resource:  resources,
+		}},
+	})
+	return string(policy), err
+}
+
+type policyDocument struct {
+	Version   string            `json:"Version"`
+	Statement []policyStatement `json:"Statement"`
+}
+
+type policyStatement struct {
+	Sid       string `json:"Sid,omitempty"`
+	Effect    string `json:"Effect"`
+	Principal any    `json:"Principal,omitempty"`
+	Action    any    `json:"Action,omitempty"`
+	Resource  any    `json:"Resource,omitempty"`
+	Condition any    `json:"Condition,omitempty"`
+}
+
+// samePolicy compares two policy documents ignoring formatting, statement ids, statement order
+// and the order of actions and resources. Servers rewrite stored policies, so text comparison fails.
+func samePolicy(a, b string) (bool, error) {
+	x, err := canonicalPolicy(a)
+	if err != nil {
+		return false, err
+	}
+	y, err := canonicalPolicy(b)
+	if err != nil {
+		return false, err
+	}
+	return x == y, nil
+}
+
+func canonicalPolicy(policy string) (string, error) {
+	if strings.TrimSpace(policy) == "" {
+		return "", nil
+	}
+
+	var document policyDocument
+	if err := json.Unmarshal([]byte(policy), &document); err != nil {
+		return "", fmt.Errorf("invalid policy: %w", err)
+	}
+
+	statements := make([]string, 0, len(document.Statement))
+	for _, statement := range document.Statement {
+		statement.Sid = ""
+		statement.Principal = canonicalPrincipal(statement.Principal)
+		statement.Action = sortedList(statement.Action)
+		statement.Resource = sortedList(statement.Resource)
+		encoded, err := json.Marshal(statement)
+		if err != nil {
+			return "", err
+		}
+		statements = append(statements, string(encoded))
+	}
+	slices.Sort(statements)
+	return strings.Join(statements, "\n"), nil
+}
+
+// canonicalPrincipal treats "*" and {"AWS": "*"} like {"AWS": ["*"]}.
+func canonicalPrincipal(principal any) any {
+	switch p := principal.(type) {
+	case string:
+		return map[string]any{"AWS": []string{p}}
+	case map[string]any:
+		result := make(map[string]any, len(p))
+		for key, value := range p {
+			result[key] = sortedList(value)
+		}
+		return result
+	}
+	return principal
+}
+
+func sortedList(value any) any {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		slices.Sort(list)
+		return list
+	}
+	return value
+}
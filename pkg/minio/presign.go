@@ -0,0 +1,183 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
+)
+
+const (
+	defaultPresignExpiry = 15 * time.Minute
+	maxPresignExpiry     = 7 * 24 * time.Hour
+)
+
+type PresignGetOptions struct {
+	// Expiry defaults to 15 minutes and can be at most 7 days.
+	Expiry time.Duration
+	// The response header overrides are returned by storage instead of the stored values,
+	// e.g. ContentDisposition: `attachment; filename="song.mp3"`.
+	ContentDisposition string
+	ContentType        string
+	CacheControl       string
+}
+
+type PresignPutOptions struct {
+	Expiry time.Duration
+	// ContentType and ContentLength are signed, so the client must send exactly these headers.
+	ContentType   string
+	ContentLength int64
+	UserMetadata  map[string]string
+}
+
+type PresignPostOptions struct {
+	Expiry time.Duration
+	// Key is the exact object name. Use KeyPrefix instead to let the client choose it.
+	Key       string
+	KeyPrefix string
+	// ContentType is an exact type or, ending with "/", a prefix such as "audio/".
+	ContentType string
+	MinSize     int64
+	MaxSize     int64
+	// ContentDisposition is stored with the object.
+	ContentDisposition string
+	UserMetadata       map[string]string
+}
+
+// PresignedURL is a request the client can make without credentials before ExpiresAt.
+// Header holds the headers it must send.
+type PresignedURL struct {
+	URL       string      `json:"url"`
+	Method    string      `json:"method"`
+	Header    http.Header `json:"headers,omitempty"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// PresignedPost is an HTML form upload: the client posts FormData plus a "file" field to URL.
+type PresignedPost struct {
+	URL       string            `json:"url"`
+	FormData  map[string]string `json:"form_data"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// ErrPresignUnsupported is returned by the presign methods of buckets whose store cannot sign URLs,
+// e.g. NewMemoryBucket and NewFileSystemBucket.
+var ErrPresignUnsupported = objectstore.ErrPresignUnsupported
+
+func (b *storeBucket) PresignGet(ctx context.Context, objectName string, opts PresignGetOptions) (PresignedURL, error) {
+	presigner, err := b.presigner()
+	if err != nil {
+		return PresignedURL{}, err
+	}
+	expiry, err := presignExpiry(opts.Expiry)
+	if err != nil {
+		return PresignedURL{}, err
+	}
+
+	params := url.Values{}
+	if opts.ContentDisposition != "" {
+		params.Set("response-content-disposition", opts.ContentDisposition)
+	}
+	if opts.ContentType != "" {
+		params.Set("response-content-type", opts.ContentType)
+	}
+	if opts.CacheControl != "" {
+		params.Set("response-cache-control", opts.CacheControl)
+	}
+
+	u, err := presigner.PresignGet(ctx, objectName, expiry, params)
+	if err != nil {
+		return PresignedURL{}, err
+	}
+	return PresignedURL{
+		URL:       u.String(),
+		Method:    http.MethodGet,
+		ExpiresAt: time.Now().Add(expiry),
+	}, nil
+}
+
+func (b *storeBucket) PresignPut(ctx context.Context, objectName string, opts PresignPutOptions) (PresignedURL, error) {
+	presigner, err := b.presigner()
+	if err != nil {
+		return PresignedURL{}, err
+	}
+	expiry, err := presignExpiry(opts.Expiry)
+	if err != nil {
+		return PresignedURL{}, err
+	}
+
+	header := http.Header{}
+	if opts.ContentType != "" {
+		header.Set("Content-Type", opts.ContentType)
+	}
+	if opts.ContentLength > 0 {
+		header.Set("Content-Length", strconv.FormatInt(opts.ContentLength, 10))
+	}
+	for key, value := range opts.UserMetadata {
+		header.Set("X-Amz-Meta-"+key, value)
+	}
+
+	u, err := presigner.PresignPut(ctx, objectName, expiry, header)
+	if err != nil {
+		return PresignedURL{}, err
+	}
+	return PresignedURL{
+		URL:       u.String(),
+		Method:    http.MethodPut,
+		Header:    header,
+		ExpiresAt: time.Now().Add(expiry),
+	}, nil
+}
+
+func (b *storeBucket) PresignPostPolicy(ctx context.Context, opts PresignPostOptions) (PresignedPost, error) {
+	presigner, err := b.presigner()
+	if err != nil {
+		return PresignedPost{}, err
+	}
+	expiry, err := presignExpiry(opts.Expiry)
+	if err != nil {
+		return PresignedPost{}, err
+	}
+	expiresAt := time.Now().Add(expiry)
+
+	u, formData, err := presigner.PresignPost(ctx, objectstore.PostPolicy{
+		Key:                opts.Key,
+		KeyPrefix:          opts.KeyPrefix,
+		ContentType:        opts.ContentType,
+		MinSize:            opts.MinSize,
+		MaxSize:            opts.MaxSize,
+		ContentDisposition: opts.ContentDisposition,
+		Metadata:           opts.UserMetadata,
+		Expires:            expiresAt,
+	})
+	if err != nil {
+		return PresignedPost{}, err
+	}
+	return PresignedPost{
+		URL:       u.String(),
+		FormData:  formData,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (b *storeBucket) presigner() (objectstore.Presigner, error) {
+	presigner, ok := b.store.(objectstore.Presigner)
+	if !ok {
+		return nil, ErrPresignUnsupported
+	}
+	return presigner, nil
+}
+
+func presignExpiry(expiry time.Duration) (time.Duration, error) {
+	if expiry == 0 {
+		return defaultPresignExpiry, nil
+	}
+	if expiry < time.Second || expiry > maxPresignExpiry {
+		return 0, fmt.Errorf("presign expiry must be between 1s and %s", maxPresignExpiry)
+	}
+	return expiry, nil
+}
@@ -0,0 +1,75 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+func TestPresignS3Store(t *testing.T) {
+	// With a region minio-go signs without requests to the server.
+	client, err := minio.New("storage.example.com", &minio.Options{
+		Creds:  credentials.NewStaticV4("access", "secret", ""),
+		Secure: true,
+		Region: "us-east-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket := NewStoreBucket(objectstore.NewS3(client, "covers", "https://cdn.example.com/covers"))
+	ctx := context.Background()
+
+	get, err := bucket.PresignGet(ctx, "a.png", PresignGetOptions{ContentDisposition: "attachment"})
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	u, err := url.Parse(get.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "storage.example.com" || u.Path != "/covers/a.png" {
+		t.Errorf("URL = %s", get.URL)
+	}
+	for _, param := range []string{"X-Amz-Signature", "X-Amz-Credential", "response-content-disposition"} {
+		if u.Query().Get(param) == "" {
+			t.Errorf("URL %s without %s", get.URL, param)
+		}
+	}
+
+	put, err := bucket.PresignPut(ctx, "a.png", PresignPutOptions{ContentType: "image/png"})
+	if err != nil {
+		t.Fatalf("PresignPut: %v", err)
+	}
+	if !strings.Contains(put.URL, "X-Amz-SignedHeaders=content-type") || put.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("PresignPut = %+v", put)
+	}
+
+	post, err := bucket.PresignPostPolicy(ctx, PresignPostOptions{KeyPrefix: "uploads/", ContentType: "image/", MaxSize: 1 << 20})
+	if err != nil {
+		t.Fatalf("PresignPostPolicy: %v", err)
+	}
+	if post.FormData["policy"] == "" || post.FormData["x-amz-signature"] == "" {
+		t.Errorf("FormData = %v", post.FormData)
+	}
+}
+
+func TestPresignUnsupported(t *testing.T) {
+	bucket := NewMemoryBucket("http://localhost/files")
+	ctx := context.Background()
+
+	if _, err := bucket.PresignGet(ctx, "a", PresignGetOptions{}); !errors.Is(err, ErrPresignUnsupported) {
+		t.Errorf("PresignGet error = %v", err)
+	}
+	if _, err := bucket.PresignPut(ctx, "a", PresignPutOptions{}); !errors.Is(err, ErrPresignUnsupported) {
+		t.Errorf("PresignPut error = %v", err)
+	}
+	if _, err := bucket.PresignPostPolicy(ctx, PresignPostOptions{Key: "a"}); !errors.Is(err, ErrPresignUnsupported) {
+		t.Errorf("PresignPostPolicy error = %v", err)
+	}
+}
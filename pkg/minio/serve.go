@@ -0,0 +1,112 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// ErrObjectNotFound is objectstore.ErrNotFound, so both packages recognize each other's errors.
+var ErrObjectNotFound = objectstore.ErrNotFound
+
+// IsNotFound reports whether err means that the object or its bucket does not exist.
+func IsNotFound(err error) bool {
+	if errors.Is(err, ErrObjectNotFound) {
+		return true
+	}
+	switch minio.ToErrorResponse(err).Code {
+	case minio.NoSuchKey, minio.NoSuchBucket:
+		return true
+	}
+	return false
+}
+
+// ServeObject writes the object with Range and If-Range support (206 and multipart/byteranges),
+// conditional GETs on ETag and Last-Modified, and HEAD requests. Objects whose reader cannot seek
+// are sent whole. A missing object returns a NotFound AppError.
+func ServeObject(w http.ResponseWriter, r *http.Request, bucket MinioBucket, objectName string) error {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		return &pkgErrors.AppError{
+			Code:     "METHOD_NOT_ALLOWED",
+			Title:    "Method Not Allowed",
+			Message:  "Objects can only be read with GET or HEAD",
+			HTTPCode: http.StatusMethodNotAllowed,
+		}
+	}
+
+	object, err := bucket.DownloadFile(r.Context(), objectName)
+	if err != nil {
+		if IsNotFound(err) {
+			return pkgErrors.NewNotFoundError("object", objectName)
+		}
+		return err
+	}
+	if closer, ok := object.Reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	header := w.Header()
+	if object.ContentType != "" {
+		header.Set("Content-Type", object.ContentType)
+	} else {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	if object.ETag != "" {
+		header.Set("ETag", quoteETag(object.ETag))
+	}
+
+	if seeker, ok := object.Reader.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", object.LastModified, seeker)
+		return nil
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" && object.ETag != "" && etagMatches(match, header.Get("ETag")) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	if !object.LastModified.IsZero() {
+		header.Set("Last-Modified", object.LastModified.UTC().Format(http.TimeFormat))
+	}
+	header.Set("Accept-Ranges", "none")
+	if object.Size >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	_, err = io.Copy(w, object.Reader)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		// The status was already sent, only the connection can report the failure.
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
+
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// etagMatches compares an If-None-Match list with etag using the weak comparison.
+func etagMatches(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
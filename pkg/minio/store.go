@@ -0,0 +1,244 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime/multipart"
+	"strings"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/objectstore"
+	"github.com/minio/minio-go/v7"
+)
+
+// storeBucket implements MinioBucket on top of an objectstore.Store, so services written against
+// MinioBucket work with any provider. Missing objects return ErrObjectNotFound, deleting a missing
+// object succeeds and metadata keys are lowercased.
+type storeBucket struct {
+	store objectstore.Store
+}
+
+// NewStoreBucket returns a MinioBucket backed by store. Uploads with options of minio.PutObjectOptions
+// that have no objectstore.PutOptions equivalent, such as ServerSideEncryption or StorageClass, fail
+// with ErrUnsupportedOption. Transfer settings such as NumThreads do not change the object and are ignored.
+func NewStoreBucket(store objectstore.Store) MinioBucket {
+	return &storeBucket{store: store}
+}
+
+// NewMemoryBucket returns a thread safe MinioBucket kept in memory, for tests and offline development.
+// Object URLs are built on baseURL.
+func NewMemoryBucket(baseURL string) MinioBucket {
+	return NewStoreBucket(objectstore.NewMemory(baseURL))
+}
+
+// NewFileSystemBucket returns a MinioBucket stored under root, for offline development.
+// Object URLs are built on baseURL.
+func NewFileSystemBucket(root string, baseURL string) (MinioBucket, error) {
+	store, err := objectstore.NewFileSystem(root, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreBucket(store), nil
+}
+
+func (b *storeBucket) Store() objectstore.Store {
+	return b.store
+}
+
+func (b *storeBucket) UploadFile(ctx context.Context, fileName string, file io.Reader, fileSize int64, opts minio.PutObjectOptions) error {
+	if err := checkPutOptions(opts); err != nil {
+		return err
+	}
+	return b.store.Put(ctx, fileName, file, fileSize, objectstore.PutOptions{
+		ContentType:        opts.ContentType,
+		ContentDisposition: opts.ContentDisposition,
+		CacheControl:       opts.CacheControl,
+		Metadata:           opts.UserMetadata,
+		Tags:               opts.UserTags,
+		PartSize:           opts.PartSize,
+	})
+}
+
+// checkPutOptions rejects the options that change the stored object but cannot be passed to a Store.
+func checkPutOptions(opts minio.PutObjectOptions) error {
+	var unsupported []string
+	if opts.ServerSideEncryption != nil {
+		unsupported = append(unsupported, "ServerSideEncryption")
+	}
+	if opts.StorageClass != "" {
+		unsupported = append(unsupported, "StorageClass")
+	}
+	if opts.ContentEncoding != "" {
+		unsupported = append(unsupported, "ContentEncoding")
+	}
+	if opts.ContentLanguage != "" {
+		unsupported = append(unsupported, "ContentLanguage")
+	}
+	if !opts.Expires.IsZero() {
+		unsupported = append(unsupported, "Expires")
+	}
+	if opts.Mode != "" || !opts.RetainUntilDate.IsZero() {
+		unsupported = append(unsupported, "Mode/RetainUntilDate")
+	}
+	if opts.LegalHold != "" {
+		unsupported = append(unsupported, "LegalHold")
+	}
+	if opts.WebsiteRedirectLocation != "" {
+		unsupported = append(unsupported, "WebsiteRedirectLocation")
+	}
+	if opts.Progress != nil {
+		unsupported = append(unsupported, "Progress")
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedOption, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+func (b *storeBucket) DownloadFile(ctx context.Context, fileName string) (ObjectData, error) {
+	object, err := b.store.Get(ctx, fileName)
+	if err != nil {
+		return ObjectData{}, err
+	}
+	return ObjectData{
+		Reader:       object.Body,
+		ContentType:  object.ContentType,
+		FileName:     object.Metadata["original_filename"],
+		Size:         object.Size,
+		ETag:         object.ETag,
+		LastModified: object.LastModified,
+		UserMetadata: object.Metadata,
+	}, nil
+}
+
+func (b *storeBucket) DeleteFile(ctx context.Context, fileName string) error {
+	return b.store.Delete(ctx, fileName)
+}
+
+func (b *storeBucket) UploadFileHeader(ctx context.Context, fileName string, fileHeader *multipart.FileHeader, opts minio.PutObjectOptions) error {
+	return uploadFileHeader(ctx, b, fileName, fileHeader, opts)
+}
+
+func (b *storeBucket) GetObjectURL(objectName string) string {
+	if objectName == "" {
+		return ""
+	}
+	return b.store.URL(objectName)
+}
+
+func (b *storeBucket) Stat(ctx context.Context, objectName string) (ObjectInfo, error) {
+	info, err := b.store.Stat(ctx, objectName)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return fromStoreInfo(info), nil
+}
+
+func (b *storeBucket) Exists(ctx context.Context, objectName string) (bool, error) {
+	_, err := b.Stat(ctx, objectName)
+	if err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (b *storeBucket) List(ctx context.Context, prefix string) iter.Seq2[ObjectInfo, error] {
+	return b.list(ctx, prefix, "")
+}
+
+func (b *storeBucket) ListPage(ctx context.Context, prefix string, startAfter string, limit int) (ObjectPage, error) {
+	return collectPage(b.list(ctx, prefix, startAfter), limit)
+}
+
+func (b *storeBucket) list(ctx context.Context, prefix string, startAfter string) iter.Seq2[ObjectInfo, error] {
+	return func(yield func(ObjectInfo, error) bool) {
+		for info, err := range b.store.List(ctx, prefix, startAfter) {
+			if !yield(fromStoreInfo(info), err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (b *storeBucket) Copy(ctx context.Context, src string, dst string) error {
+	return b.store.Copy(ctx, src, dst)
+}
+
+func (b *storeBucket) Move(ctx context.Context, src string, dst string) error {
+	if src == dst {
+		return nil
+	}
+	if err := b.Copy(ctx, src, dst); err != nil {
+		return err
+	}
+	return b.DeleteFile(ctx, src)
+}
+
+// DeleteMany deletes the objects in batches when the store supports it. Objects that could not be
+// deleted are reported in a *BatchDeleteError.
+func (b *storeBucket) DeleteMany(ctx context.Context, objectNames []string) error {
+	if deleter, ok := b.store.(objectstore.BatchDeleter); ok {
+		failed, err := deleter.DeleteMany(ctx, objectNames)
+		if err != nil {
+			return err
+		}
+		if len(failed) > 0 {
+			return &BatchDeleteError{Errors: failed}
+		}
+		return nil
+	}
+
+	failed := map[string]error{}
+	for _, name := range objectNames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := b.store.Delete(ctx, name); err != nil {
+			failed[name] = err
+		}
+	}
+	if len(failed) > 0 {
+		return &BatchDeleteError{Errors: failed}
+	}
+	return nil
+}
+
+func (b *storeBucket) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	if prefix == "" {
+		return 0, fmt.Errorf("prefix is empty, refusing to delete the whole bucket")
+	}
+
+	var names []string
+	for object, err := range b.List(ctx, prefix) {
+		if err != nil {
+			return 0, err
+		}
+		names = append(names, object.Key)
+	}
+
+	err := b.DeleteMany(ctx, names)
+	var failed *BatchDeleteError
+	if errors.As(err, &failed) {
+		return len(names) - len(failed.Errors), err
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(names), nil
+}
+
+func fromStoreInfo(info objectstore.Info) ObjectInfo {
+	return ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		UserMetadata: info.Metadata,
+	}
+}
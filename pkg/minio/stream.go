@@ -0,0 +1,250 @@
+package minio
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	defaultStreamPartSize = 16 << 20
+	defaultMaxFieldSize   = 1 << 20
+	sniffLength           = 3072
+)
+
+type StreamUploadConfig struct {
+	// MaxFileSize limits each file. The upload is aborted as soon as a file goes over it.
+	MaxFileSize int64
+	// MaxTotalSize limits the whole request body.
+	MaxTotalSize int64
+	MaxFiles     int
+	// MaxFieldSize limits each non file field. Defaults to 1MB.
+	MaxFieldSize int64
+	// AllowedTypes are matched against the type sniffed from the first bytes of each file,
+	// e.g. "audio/mpeg" or "audio/*". Empty allows any type.
+	AllowedTypes []string
+	// ObjectKey names the object of each file. Defaults to a random uuid with the file extension.
+	ObjectKey func(field, fileName string) string
+	// PartSize is the size of each part of the multipart upload and the memory used per upload.
+	// Defaults to 16MB; minio-go would otherwise buffer parts of more than 500MB for streams of unknown size.
+	PartSize uint64
+	// UserMetadata is added to every object.
+	UserMetadata map[string]string
+}
+
+type UploadedFile struct {
+	Field       string
+	FileName    string
+	Key         string
+	ContentType string
+	Size        int64
+	SHA256      string
+	MD5         string
+}
+
+type StreamUploadResult struct {
+	Files  []UploadedFile
+	Fields url.Values
+}
+
+var errFileTooLarge = errors.New("file too large")
+
+// StreamUpload reads a multipart/form-data request part by part and uploads each file part straight
+// to the bucket with an unknown size, so nothing is buffered to disk. Files are hashed while they are
+// uploaded. If any part fails, the files already uploaded are deleted.
+func StreamUpload(ctx context.Context, bucket MinioBucket, r *http.Request, cfg StreamUploadConfig) (StreamUploadResult, error) {
+	result := StreamUploadResult{Fields: url.Values{}}
+
+	if cfg.MaxTotalSize > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, cfg.MaxTotalSize)
+	}
+	if cfg.MaxFieldSize == 0 {
+		cfg.MaxFieldSize = defaultMaxFieldSize
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = defaultStreamPartSize
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return StreamUploadResult{}, pkgErrors.NewUnsupportedMediaTypeError("Content-Type must be multipart/form-data")
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			cleanup(bucket, result.Files)
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return StreamUploadResult{}, streamError(err, cfg)
+			}
+			return StreamUploadResult{}, pkgErrors.NewBadRequestError("Invalid multipart form")
+		}
+
+		if part.FileName() == "" {
+			err = readField(part, cfg.MaxFieldSize, result.Fields)
+		} else if cfg.MaxFiles > 0 && len(result.Files) >= cfg.MaxFiles {
+			err = pkgErrors.NewBadRequestError(fmt.Sprintf("At most %d files can be uploaded", cfg.MaxFiles))
+		} else {
+			var file UploadedFile
+			file, err = uploadPart(ctx, bucket, part, cfg)
+			if err == nil {
+				result.Files = append(result.Files, file)
+			}
+		}
+		part.Close()
+
+		if err != nil {
+			cleanup(bucket, result.Files)
+			return StreamUploadResult{}, streamError(err, cfg)
+		}
+	}
+}
+
+func readField(part *multipart.Part, maxSize int64, fields url.Values) error {
+	name := part.FormName()
+	value, err := io.ReadAll(io.LimitReader(part, maxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(value)) > maxSize {
+		return pkgErrors.NewPayloadTooLargeError(fmt.Sprintf("Field %s must not exceed %d bytes", name, maxSize))
+	}
+	fields.Add(name, string(value))
+	return nil
+}
+
+func uploadPart(ctx context.Context, bucket MinioBucket, part *multipart.Part, cfg StreamUploadConfig) (UploadedFile, error) {
+	file := UploadedFile{
+		Field:    part.FormName(),
+		FileName: part.FileName(),
+	}
+
+	buffered := bufio.NewReaderSize(part, sniffLength)
+	head, err := buffered.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return file, err
+	}
+
+	detected := mimetype.Detect(head)
+	if len(cfg.AllowedTypes) > 0 && !allowedType(detected, cfg.AllowedTypes) {
+		return file, pkgErrors.NewUnsupportedMediaTypeError(fmt.Sprintf("%s has type %s, expected one of [%s]", file.FileName, detected.String(), strings.Join(cfg.AllowedTypes, ", ")))
+	}
+	file.ContentType, _, _ = mime.ParseMediaType(detected.String())
+
+	if cfg.ObjectKey != nil {
+		file.Key = cfg.ObjectKey(file.Field, file.FileName)
+	} else {
+		file.Key = uuid.NewString() + strings.ToLower(path.Ext(file.FileName))
+	}
+
+	sha := sha256.New()
+	md := md5.New()
+	counter := &limitedCounter{reader: buffered, limit: cfg.MaxFileSize, hashes: []hash.Hash{sha, md}}
+
+	metadata := map[string]string{"original_filename": file.FileName}
+	for key, value := range cfg.UserMetadata {
+		metadata[key] = value
+	}
+
+	err = bucket.UploadFile(ctx, file.Key, counter, -1, minio.PutObjectOptions{
+		ContentType:  file.ContentType,
+		UserMetadata: metadata,
+		PartSize:     cfg.PartSize,
+	})
+	if counter.err != nil {
+		// The bucket may wrap or replace the read error, so report the one we produced.
+		return file, counter.err
+	}
+	if err != nil {
+		return file, err
+	}
+
+	file.Size = counter.n
+	file.SHA256 = hex.EncodeToString(sha.Sum(nil))
+	file.MD5 = hex.EncodeToString(md.Sum(nil))
+	return file, nil
+}
+
+// limitedCounter hashes and counts what is read and fails once more than limit bytes were read.
+type limitedCounter struct {
+	reader io.Reader
+	limit  int64
+	n      int64
+	hashes []hash.Hash
+	err    error
+}
+
+func (c *limitedCounter) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.n += int64(n)
+	if c.limit > 0 && c.n > c.limit {
+		c.err = errFileTooLarge
+		return 0, c.err
+	}
+	for _, h := range c.hashes {
+		h.Write(p[:n])
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.err = err
+	}
+	return n, err
+}
+
+func allowedType(detected *mimetype.MIME, allowed []string) bool {
+	for _, allowedType := range allowed {
+		if prefix, ok := strings.CutSuffix(allowedType, "/*"); ok {
+			if strings.HasPrefix(detected.String(), prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if detected.Is(allowedType) {
+			return true
+		}
+	}
+	return false
+}
+
+func streamError(err error, cfg StreamUploadConfig) error {
+	var appErr *pkgErrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return pkgErrors.NewPayloadTooLargeError(fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit))
+	}
+	if errors.Is(err, errFileTooLarge) {
+		return pkgErrors.NewPayloadTooLargeError(fmt.Sprintf("Each file must not exceed %d bytes", cfg.MaxFileSize))
+	}
+	return err
+}
+
+// cleanup deletes the objects of a failed request, with a fresh context in case ctx was canceled.
+func cleanup(bucket MinioBucket, files []UploadedFile) {
+	for _, file := range files {
+		_ = bucket.DeleteFile(context.Background(), file.Key)
+	}
+}
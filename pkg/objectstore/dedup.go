@@ -0,0 +1,361 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"iter"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBlobPrefix = ".cas/"
+
+	// Metadata of the logical objects of a ContentAddressedStore. The keys are reserved, Put
+	// replaces the values given in PutOptions.Metadata.
+	MetadataSHA256 = "cas-sha256"
+	MetadataMD5    = "cas-md5"
+	MetadataSize   = "cas-size"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ContentAddressedStore stores every distinct content once, under a key derived from its SHA-256.
+// Each key written through it is a small logical object whose metadata holds the hash, so names,
+// content types and metadata such as original_filename stay per key while the content is shared.
+// The ETag of a logical object is the MD5 of its content, like the ETag of a single part upload.
+//
+// Object URLs address stored objects directly, serve logical objects through Get or build URLs
+// from BlobKey instead. Wrap the store with minio.NewStoreBucket to use it as a MinioBucket.
+type ContentAddressedStore struct {
+	store      Store
+	blobPrefix string
+	spoolDir   string
+}
+
+type ContentAddressedOption func(c *ContentAddressedStore)
+
+// WithBlobPrefix sets the prefix of the content objects. Defaults to ".cas/".
+func WithBlobPrefix(prefix string) ContentAddressedOption {
+	return func(c *ContentAddressedStore) {
+		c.blobPrefix = strings.TrimSuffix(prefix, "/") + "/"
+	}
+}
+
+// WithSpoolDir sets the directory of the temporary files uploads are hashed into. Defaults to os.TempDir().
+func WithSpoolDir(dir string) ContentAddressedOption {
+	return func(c *ContentAddressedStore) {
+		c.spoolDir = dir
+	}
+}
+
+func NewContentAddressed(store Store, opts ...ContentAddressedOption) *ContentAddressedStore {
+	c := &ContentAddressedStore{store: store, blobPrefix: defaultBlobPrefix}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Put hashes body into a temporary file and uploads it only when no object has the same content.
+// A body shorter than size fails.
+func (c *ContentAddressedStore) Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) error {
+	if c.isBlob(key) {
+		return fmt.Errorf("invalid object name: %q is reserved", key)
+	}
+
+	spool, err := os.CreateTemp(c.spoolDir, "objectstore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	checksum, md5sum := sha256.New(), md5.New()
+	written, err := io.Copy(io.MultiWriter(spool, checksum, md5sum), newSizedReader(body, size))
+	if err != nil {
+		return err
+	}
+	sum := hex.EncodeToString(checksum.Sum(nil))
+
+	// Reusing a content object refreshes its LastModified, so a concurrent Prune keeps it until
+	// the key that references it is written.
+	blobKey := c.blobKey(sum)
+	err = c.store.Copy(ctx, blobKey, blobKey)
+	if IsNotFound(err) {
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		err = c.store.Put(ctx, blobKey, spool, written, PutOptions{ContentType: opts.ContentType, PartSize: opts.PartSize})
+	}
+	if err != nil {
+		return err
+	}
+
+	metadata := lowerKeys(opts.Metadata)
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata[MetadataSHA256] = sum
+	metadata[MetadataMD5] = hex.EncodeToString(md5sum.Sum(nil))
+	metadata[MetadataSize] = strconv.FormatInt(written, 10)
+	opts.Metadata = metadata
+	return c.store.Put(ctx, key, bytes.NewReader(nil), 0, opts)
+}
+
+// Get returns the content of key. Reading Body to the end fails with ErrChecksumMismatch
+// when the content does not match its hash.
+func (c *ContentAddressedStore) Get(ctx context.Context, key string) (Object, error) {
+	info, sum, err := c.stat(ctx, key)
+	if err != nil {
+		return Object{}, err
+	}
+	if sum == "" {
+		return c.store.Get(ctx, key)
+	}
+
+	blob, err := c.store.Get(ctx, c.blobKey(sum))
+	if err != nil {
+		return Object{}, fmt.Errorf("content of %s: %w", key, err)
+	}
+	return Object{Info: info, Body: newVerifyingBody(blob.Body, sum, info.Size)}, nil
+}
+
+func (c *ContentAddressedStore) Stat(ctx context.Context, key string) (Info, error) {
+	info, _, err := c.stat(ctx, key)
+	return info, err
+}
+
+// Delete deletes key. Its content is kept until Prune since other keys may share it.
+func (c *ContentAddressedStore) Delete(ctx context.Context, key string) error {
+	if c.isBlob(key) {
+		return nil
+	}
+	return c.store.Delete(ctx, key)
+}
+
+// List iterates the logical objects, which costs a Stat per object on stores that do not list metadata.
+func (c *ContentAddressedStore) List(ctx context.Context, prefix string, startAfter string) iter.Seq2[Info, error] {
+	return func(yield func(Info, error) bool) {
+		for object, err := range c.store.List(ctx, prefix, startAfter) {
+			if err != nil {
+				yield(Info{}, err)
+				return
+			}
+			if c.isBlob(object.Key) {
+				continue
+			}
+			info, err := c.Stat(ctx, object.Key)
+			if IsNotFound(err) {
+				continue
+			}
+			if !yield(info, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Copy copies the logical object only, both keys share the content.
+func (c *ContentAddressedStore) Copy(ctx context.Context, src string, dst string) error {
+	if c.isBlob(dst) {
+		return fmt.Errorf("invalid object name: %q is reserved", dst)
+	}
+	return c.store.Copy(ctx, src, dst)
+}
+
+func (c *ContentAddressedStore) URL(key string) string {
+	return c.store.URL(key)
+}
+
+// BlobKey returns the key of the content of key in the underlying store. Content objects never
+// change, so their URLs can be cached forever.
+func (c *ContentAddressedStore) BlobKey(ctx context.Context, key string) (string, error) {
+	_, sum, err := c.stat(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if sum == "" {
+		return key, nil
+	}
+	return c.blobKey(sum), nil
+}
+
+// PresignGet signs a GET of the content of key when the underlying store is a Presigner. The
+// response has the content type of key unless params override it.
+func (c *ContentAddressedStore) PresignGet(ctx context.Context, key string, expiry time.Duration, params url.Values) (*url.URL, error) {
+	presigner, ok := c.store.(Presigner)
+	if !ok {
+		return nil, ErrPresignUnsupported
+	}
+	info, sum, err := c.stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if sum == "" {
+		return presigner.PresignGet(ctx, key, expiry, params)
+	}
+	if params.Get("response-content-type") == "" {
+		params = maps.Clone(params)
+		if params == nil {
+			params = url.Values{}
+		}
+		params.Set("response-content-type", info.ContentType)
+	}
+	return presigner.PresignGet(ctx, c.blobKey(sum), expiry, params)
+}
+
+// PresignPut returns ErrPresignUnsupported, uploads must be hashed by Put.
+func (c *ContentAddressedStore) PresignPut(ctx context.Context, key string, expiry time.Duration, header http.Header) (*url.URL, error) {
+	return nil, ErrPresignUnsupported
+}
+
+// PresignPost returns ErrPresignUnsupported, uploads must be hashed by Put.
+func (c *ContentAddressedStore) PresignPost(ctx context.Context, policy PostPolicy) (*url.URL, map[string]string, error) {
+	return nil, nil, ErrPresignUnsupported
+}
+
+// Prune deletes the content objects older than olderThan that no key references, and returns
+// how many were deleted. olderThan must cover the longest upload, whose content is stored before
+// its key. Each content object is checked again right before it is deleted, since a Put reusing
+// it refreshes its LastModified.
+func (c *ContentAddressedStore) Prune(ctx context.Context, olderThan time.Duration) (int, error) {
+	referenced := map[string]bool{}
+	for object, err := range c.List(ctx, "", "") {
+		if err != nil {
+			return 0, err
+		}
+		if sum := object.Metadata[MetadataSHA256]; sum != "" {
+			referenced[c.blobKey(sum)] = true
+		}
+	}
+
+	deleted := 0
+	before := time.Now().Add(-olderThan)
+	for blob, err := range c.store.List(ctx, c.blobPrefix, "") {
+		if err != nil {
+			return deleted, err
+		}
+		if referenced[blob.Key] || blob.LastModified.After(before) {
+			continue
+		}
+		current, err := c.store.Stat(ctx, blob.Key)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		if current.LastModified.After(before) {
+			continue
+		}
+		if err := c.store.Delete(ctx, blob.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// stat returns the Info of the logical object and its hash, which is empty for objects stored
+// without a ContentAddressedStore. These are returned as they are.
+func (c *ContentAddressedStore) stat(ctx context.Context, key string) (Info, string, error) {
+	if c.isBlob(key) {
+		return Info{}, "", notFound(key)
+	}
+	info, err := c.store.Stat(ctx, key)
+	if err != nil {
+		return Info{}, "", err
+	}
+
+	sum := info.Metadata[MetadataSHA256]
+	if sum == "" {
+		return info, "", nil
+	}
+	size, err := strconv.ParseInt(info.Metadata[MetadataSize], 10, 64)
+	if err != nil {
+		return Info{}, "", fmt.Errorf("invalid size of %s: %w", key, err)
+	}
+	info.Size = size
+	info.ETag = info.Metadata[MetadataMD5]
+	info.Metadata = maps.Clone(info.Metadata)
+	return info, sum, nil
+}
+
+func (c *ContentAddressedStore) blobKey(sum string) string {
+	return c.blobPrefix + sum[:2] + "/" + sum
+}
+
+func (c *ContentAddressedStore) isBlob(key string) bool {
+	return strings.HasPrefix(key, c.blobPrefix)
+}
+
+// verifyingBody checks the hash of the content when it is read from the start to the end. The
+// check runs once size bytes are read, since readers such as http.ServeContent stop there without
+// reading EOF, and withholds the last bytes on a mismatch so the content is never received whole.
+type verifyingBody struct {
+	body   io.ReadCloser
+	hash   hash.Hash
+	sum    string
+	size   int64
+	read   int64
+	verify bool
+}
+
+func newVerifyingBody(body io.ReadCloser, sum string, size int64) io.ReadCloser {
+	verifying := &verifyingBody{body: body, hash: sha256.New(), sum: sum, size: size, verify: true}
+	if _, ok := body.(io.Seeker); ok {
+		return &verifyingSeekBody{verifying}
+	}
+	return verifying
+}
+
+func (v *verifyingBody) Read(p []byte) (int, error) {
+	n, err := v.body.Read(p)
+	if !v.verify {
+		return n, err
+	}
+	v.hash.Write(p[:n])
+	v.read += int64(n)
+	if v.read < v.size && !errors.Is(err, io.EOF) {
+		return n, err
+	}
+	v.verify = false
+	if hex.EncodeToString(v.hash.Sum(nil)) != v.sum {
+		return 0, fmt.Errorf("%w: content does not match sha256 %s", ErrChecksumMismatch, v.sum)
+	}
+	return n, err
+}
+
+func (v *verifyingBody) Close() error {
+	return v.body.Close()
+}
+
+// verifyingSeekBody keeps the body seekable for range requests. Reads that do not start at
+// the beginning are not verified.
+type verifyingSeekBody struct {
+	*verifyingBody
+}
+
+func (v *verifyingSeekBody) Seek(offset int64, whence int) (int64, error) {
+	position, err := v.body.(io.Seeker).Seek(offset, whence)
+	if err != nil {
+		return position, err
+	}
+	v.hash.Reset()
+	v.read = 0
+	v.verify = position == 0
+	return position, nil
+}
@@ -0,0 +1,238 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestContentAddressed(t *testing.T) (*ContentAddressedStore, Store) {
+	t.Helper()
+	store := NewMemory("http://localhost/files")
+	return NewContentAddressed(store, WithSpoolDir(t.TempDir())), store
+}
+
+func put(t *testing.T, store Store, key string, content string, opts PutOptions) {
+	t.Helper()
+	if err := store.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), opts); err != nil {
+		t.Fatalf("Put %s: %v", key, err)
+	}
+}
+
+func keys(t *testing.T, store Store, prefix string) []string {
+	t.Helper()
+	var result []string
+	for info, err := range store.List(context.Background(), prefix, "") {
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		result = append(result, info.Key)
+	}
+	return result
+}
+
+func TestContentAddressedPut(t *testing.T) {
+	cas, store := newTestContentAddressed(t)
+	ctx := context.Background()
+
+	put(t, cas, "songs/a.mp3", "audio", PutOptions{
+		ContentType: "audio/mpeg",
+		Metadata:    map[string]string{"original_filename": "a.mp3", "sha256": "user value", MetadataSize: "1"},
+	})
+	put(t, cas, "songs/b.mp3", "audio", PutOptions{ContentType: "audio/mpeg"})
+
+	if blobs := keys(t, store, defaultBlobPrefix); len(blobs) != 1 {
+		t.Fatalf("content objects = %v, want one shared by both keys", blobs)
+	}
+
+	info, err := cas.Stat(ctx, "songs/a.mp3")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	sha := sha256.Sum256([]byte("audio"))
+	md := md5.Sum([]byte("audio"))
+	if info.Size != 5 || info.ETag != hex.EncodeToString(md[:]) || info.ContentType != "audio/mpeg" {
+		t.Errorf("Stat = %+v", info)
+	}
+	tests := map[string]string{
+		"original_filename": "a.mp3",
+		"sha256":            "user value",
+		MetadataSize:        "5",
+		MetadataSHA256:      hex.EncodeToString(sha[:]),
+	}
+	for key, want := range tests {
+		if got := info.Metadata[key]; got != want {
+			t.Errorf("metadata %s = %q, want %q", key, got, want)
+		}
+	}
+
+	blobKey, err := cas.BlobKey(ctx, "songs/b.mp3")
+	if err != nil || blobKey != defaultBlobPrefix+hex.EncodeToString(sha[:1])+"/"+hex.EncodeToString(sha[:]) {
+		t.Errorf("BlobKey = %q, %v", blobKey, err)
+	}
+
+	if err := cas.Put(ctx, defaultBlobPrefix+"x", strings.NewReader("a"), 1, PutOptions{}); err == nil {
+		t.Error("Put of a content key succeeded")
+	}
+	if err := cas.Put(ctx, "short", strings.NewReader("a"), 2, PutOptions{}); err == nil {
+		t.Error("Put of a short body succeeded")
+	}
+	if got := keys(t, cas, ""); strings.Join(got, ",") != "songs/a.mp3,songs/b.mp3" {
+		t.Errorf("List = %v, want the logical objects only", got)
+	}
+}
+
+func TestContentAddressedGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  string
+		read    func(body io.ReadCloser, size int64) error
+		wantErr error
+	}{
+		{
+			name:   "read to EOF",
+			stored: "content",
+			read:   func(body io.ReadCloser, size int64) error { _, err := io.ReadAll(body); return err },
+		},
+		{
+			name:    "corrupt read to EOF",
+			stored:  "CONTENT",
+			read:    func(body io.ReadCloser, size int64) error { _, err := io.ReadAll(body); return err },
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			// http.ServeContent copies exactly size bytes and never reads EOF.
+			name:    "corrupt read of size bytes",
+			stored:  "CONTENT",
+			read:    func(body io.ReadCloser, size int64) error { _, err := io.CopyN(io.Discard, body, size); return err },
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name:   "corrupt range read",
+			stored: "CONTENT",
+			read: func(body io.ReadCloser, size int64) error {
+				if _, err := body.(io.Seeker).Seek(2, io.SeekStart); err != nil {
+					return err
+				}
+				_, err := io.ReadAll(body)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cas, store := newTestContentAddressed(t)
+			ctx := context.Background()
+			put(t, cas, "a", "content", PutOptions{})
+			blobKey, err := cas.BlobKey(ctx, "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			put(t, store, blobKey, tt.stored, PutOptions{})
+
+			object, err := cas.Get(ctx, "a")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			defer object.Body.Close()
+			if err := tt.read(object.Body, object.Size); !errors.Is(err, tt.wantErr) {
+				t.Errorf("read error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContentAddressedPrune(t *testing.T) {
+	cas, store := newTestContentAddressed(t)
+	ctx := context.Background()
+	put(t, cas, "kept", "shared", PutOptions{})
+	put(t, cas, "deleted", "orphan", PutOptions{})
+	if err := cas.Delete(ctx, "deleted"); err != nil {
+		t.Fatal(err)
+	}
+
+	if deleted, err := cas.Prune(ctx, time.Hour); err != nil || deleted != 0 {
+		t.Fatalf("Prune of recent content = %d, %v, want 0", deleted, err)
+	}
+	deleted, err := cas.Prune(ctx, 0)
+	if err != nil || deleted != 1 {
+		t.Fatalf("Prune = %d, %v, want 1", deleted, err)
+	}
+	if blobs := keys(t, store, defaultBlobPrefix); len(blobs) != 1 {
+		t.Errorf("content objects = %v, want the referenced one", blobs)
+	}
+	object, err := cas.Get(ctx, "kept")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer object.Body.Close()
+	if content, err := io.ReadAll(object.Body); err != nil || string(content) != "shared" {
+		t.Errorf("content = %q, %v", content, err)
+	}
+}
+
+// putWhileListing reuses content while Prune lists the content objects, after it collected the references.
+type putWhileListing struct {
+	Store
+	onList func()
+}
+
+func (s *putWhileListing) List(ctx context.Context, prefix string, startAfter string) iter.Seq2[Info, error] {
+	if prefix == defaultBlobPrefix && s.onList != nil {
+		return func(yield func(Info, error) bool) {
+			for info, err := range s.Store.List(ctx, prefix, startAfter) {
+				onList := s.onList
+				s.onList = nil
+				if onList != nil {
+					onList()
+				}
+				if !yield(info, err) {
+					return
+				}
+			}
+		}
+	}
+	return s.Store.List(ctx, prefix, startAfter)
+}
+
+func TestContentAddressedPruneConcurrentPut(t *testing.T) {
+	store := &putWhileListing{Store: NewMemory("")}
+	cas := NewContentAddressed(store, WithSpoolDir(t.TempDir()))
+	ctx := context.Background()
+	put(t, cas, "old", "content", PutOptions{})
+	if err := cas.Delete(ctx, "old"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	store.onList = func() { put(t, cas, "new", "content", PutOptions{}) }
+	if deleted, err := cas.Prune(ctx, 5*time.Millisecond); err != nil || deleted != 0 {
+		t.Fatalf("Prune = %d, %v, want the reused content kept", deleted, err)
+	}
+	object, err := cas.Get(ctx, "new")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	object.Body.Close()
+}
+
+func TestContentAddressedPresign(t *testing.T) {
+	cas, _ := newTestContentAddressed(t)
+	ctx := context.Background()
+	put(t, cas, "a", "content", PutOptions{})
+
+	if _, err := cas.PresignGet(ctx, "a", time.Minute, nil); !errors.Is(err, ErrPresignUnsupported) {
+		t.Errorf("PresignGet error = %v", err)
+	}
+	if _, err := cas.PresignPut(ctx, "a", time.Minute, nil); !errors.Is(err, ErrPresignUnsupported) {
+		t.Errorf("PresignPut error = %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	objectsDir = "objects"
+	metaDir    = "meta"
+	metaSuffix = ".json"
+)
+
+// fileMeta is the sidecar file stored next to every object.
+type fileMeta struct {
+	ContentType        string            `json:"content_type"`
+	ContentDisposition string            `json:"content_disposition,omitempty"`
+	CacheControl       string            `json:"cache_control,omitempty"`
+	Metadata           map[string]string `json:"user_metadata,omitempty"`
+	ETag               string            `json:"etag"`
+	Size               int64             `json:"size"`
+	LastModified       time.Time         `json:"last_modified"`
+}
+
+type fileSystemStore struct {
+	mutex   sync.RWMutex
+	root    string
+	baseURL string
+}
+
+// NewFileSystem returns a Store kept under root, for offline development. Object content is
+// stored in root/objects and its content type and metadata in sidecar files in root/meta.
+// Object URLs are built on baseURL.
+func NewFileSystem(root string, baseURL string) (Store, error) {
+	for _, dir := range []string{objectsDir, metaDir} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &fileSystemStore{root: root, baseURL: baseURL}, nil
+}
+
+func (f *fileSystemStore) Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	return f.put(key, newSizedReader(body, size), newInfo(key, opts))
+}
+
+func (f *fileSystemStore) put(key string, body io.Reader, info Info) error {
+	// Write to a temporary file first so readers never see a partial object.
+	temp, err := os.CreateTemp(filepath.Join(f.root, objectsDir), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	hash := md5.New()
+	size, err := io.Copy(io.MultiWriter(temp, hash), body)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(fileMeta{
+		ContentType:        info.ContentType,
+		ContentDisposition: info.ContentDisposition,
+		CacheControl:       info.CacheControl,
+		Metadata:           info.Metadata,
+		ETag:               hex.EncodeToString(hash.Sum(nil)),
+		Size:               size,
+		LastModified:       time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	objectPath, metaPath := f.paths(key)
+	for _, path := range []string{objectPath, metaPath} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(metaPath, meta, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), objectPath)
+}
+
+func (f *fileSystemStore) Get(ctx context.Context, key string) (Object, error) {
+	if err := ctx.Err(); err != nil {
+		return Object{}, err
+	}
+	if validateKey(key) != nil {
+		return Object{}, notFound(key)
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	info, err := f.readMeta(key)
+	if err != nil {
+		return Object{}, err
+	}
+	objectPath, _ := f.paths(key)
+	// Renaming over the path on a later put keeps this file readable.
+	file, err := os.Open(objectPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Object{}, notFound(key)
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	return Object{Info: info, Body: file}, nil
+}
+
+func (f *fileSystemStore) Stat(ctx context.Context, key string) (Info, error) {
+	if err := ctx.Err(); err != nil {
+		return Info{}, err
+	}
+	if validateKey(key) != nil {
+		return Info{}, notFound(key)
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.readMeta(key)
+}
+
+func (f *fileSystemStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if validateKey(key) != nil {
+		return nil
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	objectPath, metaPath := f.paths(key)
+	for _, path := range []string{objectPath, metaPath} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	f.removeEmptyDirs(filepath.Dir(objectPath), filepath.Join(f.root, objectsDir))
+	f.removeEmptyDirs(filepath.Dir(metaPath), filepath.Join(f.root, metaDir))
+	return nil
+}
+
+func (f *fileSystemStore) List(ctx context.Context, prefix string, startAfter string) iter.Seq2[Info, error] {
+	return func(yield func(Info, error) bool) {
+		keys, err := f.keys()
+		if err != nil {
+			yield(Info{}, err)
+			return
+		}
+		for info, err := range listKeys(ctx, keys, prefix, startAfter, func(key string) (Info, error) {
+			return f.Stat(ctx, key)
+		}) {
+			if !yield(info, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *fileSystemStore) Copy(ctx context.Context, src string, dst string) error {
+	if err := validateKey(dst); err != nil {
+		return err
+	}
+	object, err := f.Get(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer object.Body.Close()
+
+	return f.put(dst, object.Body, object.Info)
+}
+
+func (f *fileSystemStore) URL(key string) string {
+	return joinURL(f.baseURL, key)
+}
+
+func (f *fileSystemStore) keys() ([]string, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	root := filepath.Join(f.root, metaDir)
+	var keys []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, metaSuffix) {
+			return nil
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, strings.TrimSuffix(filepath.ToSlash(relative), metaSuffix))
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// readMeta must be called with the mutex held.
+func (f *fileSystemStore) readMeta(key string) (Info, error) {
+	_, metaPath := f.paths(key)
+	content, err := os.ReadFile(metaPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Info{}, notFound(key)
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	var meta fileMeta
+	if err := json.Unmarshal(content, &meta); err != nil {
+		return Info{}, err
+	}
+	return Info{
+		Key:                key,
+		Size:               meta.Size,
+		ContentType:        meta.ContentType,
+		ContentDisposition: meta.ContentDisposition,
+		CacheControl:       meta.CacheControl,
+		ETag:               meta.ETag,
+		LastModified:       meta.LastModified,
+		Metadata:           meta.Metadata,
+	}, nil
+}
+
+func (f *fileSystemStore) paths(key string) (string, string) {
+	relative := filepath.FromSlash(key)
+	return filepath.Join(f.root, objectsDir, relative), filepath.Join(f.root, metaDir, relative+metaSuffix)
+}
+
+// removeEmptyDirs removes dir and its parents up to root while they are empty, like prefixes disappear in S3.
+func (f *fileSystemStore) removeEmptyDirs(dir string, root string) {
+	for dir != root && strings.HasPrefix(dir, root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
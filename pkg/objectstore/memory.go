@@ -0,0 +1,138 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	data []byte
+	info Info
+}
+
+type memoryStore struct {
+	mutex   sync.RWMutex
+	objects map[string]memoryObject
+	baseURL string
+}
+
+// NewMemory returns a thread safe Store kept in memory, for tests and offline development.
+// Object URLs are built on baseURL.
+func NewMemory(baseURL string) Store {
+	return &memoryStore{objects: map[string]memoryObject{}, baseURL: baseURL}
+}
+
+func (m *memoryStore) Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	return m.put(key, newSizedReader(body, size), newInfo(key, opts))
+}
+
+func (m *memoryStore) put(key string, body io.Reader, info Info) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	sum := md5.Sum(content)
+	info.Key = key
+	info.Size = int64(len(content))
+	info.ETag = hex.EncodeToString(sum[:])
+	info.LastModified = time.Now().UTC()
+	info.Metadata = maps.Clone(info.Metadata)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.objects[key] = memoryObject{data: content, info: info}
+	return nil
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) (Object, error) {
+	if err := ctx.Err(); err != nil {
+		return Object{}, err
+	}
+	object, ok := m.get(key)
+	if !ok {
+		return Object{}, notFound(key)
+	}
+	// Stored content is never modified, a put replaces it.
+	return Object{Info: object.info, Body: nopCloser{bytes.NewReader(object.data)}}, nil
+}
+
+func (m *memoryStore) Stat(ctx context.Context, key string) (Info, error) {
+	if err := ctx.Err(); err != nil {
+		return Info{}, err
+	}
+	object, ok := m.get(key)
+	if !ok {
+		return Info{}, notFound(key)
+	}
+	return object.info, nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memoryStore) List(ctx context.Context, prefix string, startAfter string) iter.Seq2[Info, error] {
+	m.mutex.RLock()
+	keys := slices.Sorted(maps.Keys(m.objects))
+	m.mutex.RUnlock()
+
+	return listKeys(ctx, keys, prefix, startAfter, func(key string) (Info, error) {
+		return m.Stat(ctx, key)
+	})
+}
+
+func (m *memoryStore) Copy(ctx context.Context, src string, dst string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateKey(dst); err != nil {
+		return err
+	}
+	object, ok := m.get(src)
+	if !ok {
+		return notFound(src)
+	}
+	return m.put(dst, bytes.NewReader(object.data), object.info)
+}
+
+func (m *memoryStore) URL(key string) string {
+	return joinURL(m.baseURL, key)
+}
+
+// get returns a copy of the object that is safe to use without the lock.
+func (m *memoryStore) get(key string) (memoryObject, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	object, ok := m.objects[key]
+	object.info.Metadata = maps.Clone(object.info.Metadata)
+	return object, ok
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
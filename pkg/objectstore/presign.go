@@ -0,0 +1,38 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrPresignUnsupported is returned when a store cannot sign URLs, e.g. the memory and filesystem stores.
+var ErrPresignUnsupported = errors.New("store cannot presign URLs")
+
+// PostPolicy describes the HTML form uploads a presigned POST accepts.
+type PostPolicy struct {
+	// Key is the exact object name. Use KeyPrefix instead to let the client choose it.
+	Key       string
+	KeyPrefix string
+	// ContentType is an exact type or, ending with "/", a prefix such as "audio/".
+	ContentType string
+	// MaxSize limits the upload size when positive.
+	MinSize            int64
+	MaxSize            int64
+	ContentDisposition string
+	Metadata           map[string]string
+	Expires            time.Time
+}
+
+// Presigner is implemented by stores that can sign URLs, so clients transfer content without
+// going through the application.
+type Presigner interface {
+	// PresignGet signs a GET of key. params may override response headers, e.g. response-content-disposition.
+	PresignGet(ctx context.Context, key string, expiry time.Duration, params url.Values) (*url.URL, error)
+	// PresignPut signs a PUT of key. header is signed, so the client must send exactly these headers.
+	PresignPut(ctx context.Context, key string, expiry time.Duration, header http.Header) (*url.URL, error)
+	// PresignPost signs policy and returns the form fields the client posts along with a "file" field.
+	PresignPost(ctx context.Context, policy PostPolicy) (*url.URL, map[string]string, error)
+}
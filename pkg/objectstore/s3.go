@@ -0,0 +1,258 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// s3PartSize bounds the memory used by uploads of unknown size, minio-go defaults to about 550MB.
+const s3PartSize = 16 << 20
+
+type s3Store struct {
+	client        *minio.Client
+	presignClient *minio.Client
+	bucketName    string
+	baseURL       string
+}
+
+type S3Option func(s *s3Store)
+
+// WithPresignClient signs URLs with client instead of the store client. Use a client created with the
+// public endpoint when the store client uses an internal one, since the host is part of the signature.
+func WithPresignClient(client *minio.Client) S3Option {
+	return func(s *s3Store) {
+		if client != nil {
+			s.presignClient = client
+		}
+	}
+}
+
+// NewS3 returns a Store for an existing bucket of MinIO or any S3 compatible provider. It also
+// implements Presigner and BatchDeleter. Object URLs are built on baseURL, e.g. "https://cdn.example.com/covers".
+func NewS3(client *minio.Client, bucketName string, baseURL string, opts ...S3Option) Store {
+	s := &s3Store{client: client, presignClient: client, bucketName: bucketName, baseURL: baseURL}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) error {
+	putOpts := minio.PutObjectOptions{
+		ContentType:        opts.ContentType,
+		ContentDisposition: opts.ContentDisposition,
+		CacheControl:       opts.CacheControl,
+		UserMetadata:       lowerKeys(opts.Metadata),
+		UserTags:           opts.Tags,
+		PartSize:           opts.PartSize,
+	}
+	if putOpts.ContentType == "" {
+		putOpts.ContentType = defaultContentType
+	}
+	if size < 0 && putOpts.PartSize == 0 {
+		putOpts.PartSize = s3PartSize
+	}
+	_, err := s.client.PutObject(ctx, s.bucketName, key, body, size, putOpts)
+	return s.wrap(key, err)
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (Object, error) {
+	object, err := s.client.GetObject(ctx, s.bucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		return Object{}, s.wrap(key, err)
+	}
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return Object{}, s.wrap(key, err)
+	}
+	return Object{Info: s3Info(info), Body: object}, nil
+}
+
+func (s *s3Store) Stat(ctx context.Context, key string) (Info, error) {
+	info, err := s.client.StatObject(ctx, s.bucketName, key, minio.StatObjectOptions{})
+	if err != nil {
+		return Info{}, s.wrap(key, err)
+	}
+	return s3Info(info), nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	err := s.client.RemoveObject(ctx, s.bucketName, key, minio.RemoveObjectOptions{})
+	if IsNotFound(s.wrap(key, err)) {
+		return nil
+	}
+	return err
+}
+
+func (s *s3Store) List(ctx context.Context, prefix string, startAfter string) iter.Seq2[Info, error] {
+	return func(yield func(Info, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		objects := s.client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{
+			Prefix:     prefix,
+			StartAfter: startAfter,
+			Recursive:  true,
+		})
+		for object := range objects {
+			if object.Err != nil {
+				yield(Info{}, object.Err)
+				return
+			}
+			if !yield(s3Info(object), nil) {
+				return
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			yield(Info{}, err)
+		}
+	}
+}
+
+func (s *s3Store) Copy(ctx context.Context, src string, dst string) error {
+	dstOpts := minio.CopyDestOptions{Bucket: s.bucketName, Object: dst}
+	if src == dst {
+		// S3 only copies an object onto itself when the metadata is replaced, here with its own.
+		info, err := s.Stat(ctx, src)
+		if err != nil {
+			return err
+		}
+		metadata := map[string]string{"Content-Type": info.ContentType}
+		if info.ContentDisposition != "" {
+			metadata["Content-Disposition"] = info.ContentDisposition
+		}
+		if info.CacheControl != "" {
+			metadata["Cache-Control"] = info.CacheControl
+		}
+		for key, value := range info.Metadata {
+			metadata["X-Amz-Meta-"+key] = value
+		}
+		dstOpts.ReplaceMetadata = true
+		dstOpts.UserMetadata = metadata
+	}
+	_, err := s.client.CopyObject(ctx, dstOpts, minio.CopySrcOptions{Bucket: s.bucketName, Object: src})
+	return s.wrap(src, err)
+}
+
+func (s *s3Store) DeleteMany(ctx context.Context, keys []string) (map[string]error, error) {
+	objects := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objects)
+		for _, key := range keys {
+			select {
+			case objects <- minio.ObjectInfo{Key: key}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	failed := map[string]error{}
+	for result := range s.client.RemoveObjects(ctx, s.bucketName, objects, minio.RemoveObjectsOptions{}) {
+		if result.Err != nil && !IsNotFound(s.wrap(result.ObjectName, result.Err)) {
+			failed[result.ObjectName] = result.Err
+		}
+	}
+	return failed, ctx.Err()
+}
+
+func (s *s3Store) PresignGet(ctx context.Context, key string, expiry time.Duration, params url.Values) (*url.URL, error) {
+	return s.presignClient.PresignedGetObject(ctx, s.bucketName, key, expiry, params)
+}
+
+func (s *s3Store) PresignPut(ctx context.Context, key string, expiry time.Duration, header http.Header) (*url.URL, error) {
+	return s.presignClient.PresignHeader(ctx, http.MethodPut, s.bucketName, key, expiry, nil, header)
+}
+
+func (s *s3Store) PresignPost(ctx context.Context, policy PostPolicy) (*url.URL, map[string]string, error) {
+	post := minio.NewPostPolicy()
+	if err := post.SetBucket(s.bucketName); err != nil {
+		return nil, nil, err
+	}
+	if err := post.SetExpires(policy.Expires.UTC()); err != nil {
+		return nil, nil, err
+	}
+
+	var err error
+	switch {
+	case policy.Key != "":
+		err = post.SetKey(policy.Key)
+	case policy.KeyPrefix != "":
+		err = post.SetKeyStartsWith(policy.KeyPrefix)
+	default:
+		err = errors.New("key or key prefix is required")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if policy.ContentType != "" {
+		if strings.HasSuffix(policy.ContentType, "/") {
+			err = post.SetContentTypeStartsWith(policy.ContentType)
+		} else {
+			err = post.SetContentType(policy.ContentType)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if policy.MaxSize > 0 {
+		if err := post.SetContentLengthRange(policy.MinSize, policy.MaxSize); err != nil {
+			return nil, nil, err
+		}
+	}
+	if policy.ContentDisposition != "" {
+		if err := post.SetContentDisposition(policy.ContentDisposition); err != nil {
+			return nil, nil, err
+		}
+	}
+	for key, value := range policy.Metadata {
+		if err := post.SetUserMetadata(key, value); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := post.SetSuccessStatusAction("201"); err != nil {
+		return nil, nil, err
+	}
+
+	return s.presignClient.PresignedPostPolicy(ctx, post)
+}
+
+func (s *s3Store) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+// wrap makes missing objects match ErrNotFound while keeping the provider error.
+func (s *s3Store) wrap(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch minio.ToErrorResponse(err).Code {
+	case minio.NoSuchKey, minio.NoSuchBucket:
+		return fmt.Errorf("%w: %s: %w", ErrNotFound, key, err)
+	}
+	return err
+}
+
+func s3Info(info minio.ObjectInfo) Info {
+	return Info{
+		Key:                info.Key,
+		Size:               info.Size,
+		ContentType:        info.ContentType,
+		ContentDisposition: info.Metadata.Get("Content-Disposition"),
+		CacheControl:       info.Metadata.Get("Cache-Control"),
+		ETag:               info.ETag,
+		LastModified:       info.LastModified,
+		Metadata:           lowerKeys(info.UserMetadata),
+	}
+}
@@ -0,0 +1,172 @@
+// Package objectstore is a provider neutral object storage API with MinIO/S3, filesystem and memory adapters.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"time"
+)
+
+const defaultContentType = "application/octet-stream"
+
+var ErrNotFound = errors.New("object not found")
+
+type PutOptions struct {
+	// ContentType defaults to application/octet-stream.
+	ContentType        string
+	ContentDisposition string
+	CacheControl       string
+	// Metadata keys are stored lowercased, e.g. "original_filename".
+	Metadata map[string]string
+	// Tags are used by provider lifecycle rules and are not returned by Stat.
+	Tags map[string]string
+	// PartSize is the size of each part on stores that upload in parts, and the memory they use per
+	// upload. Defaults to 16MB for bodies of unknown size.
+	PartSize uint64
+}
+
+type Info struct {
+	Key                string
+	Size               int64
+	ContentType        string
+	ContentDisposition string
+	CacheControl       string
+	ETag               string
+	LastModified       time.Time
+	// Only Key, Size, ETag and LastModified are guaranteed in listings.
+	Metadata map[string]string
+}
+
+// Object is returned by Get. Body also implements io.Seeker when the store supports it.
+type Object struct {
+	Info
+	Body io.ReadCloser
+}
+
+type Store interface {
+	// Put stores body under key, replacing any existing object. size is -1 when unknown,
+	// otherwise a body shorter than size fails.
+	Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) error
+	// Get returns the object. The caller must close Body.
+	Get(ctx context.Context, key string) (Object, error)
+	Stat(ctx context.Context, key string) (Info, error)
+	// Delete succeeds when the object does not exist.
+	Delete(ctx context.Context, key string) error
+	// List iterates the objects under prefix with a key after startAfter, in key order.
+	List(ctx context.Context, prefix string, startAfter string) iter.Seq2[Info, error]
+	// Copy copies an object keeping its content type and metadata. Copying an object onto itself
+	// refreshes its LastModified.
+	Copy(ctx context.Context, src string, dst string) error
+	// URL returns the public URL of key, or the URL of the bucket when key is empty.
+	URL(key string) string
+}
+
+// BatchDeleter is implemented by stores that delete many objects in a few requests.
+type BatchDeleter interface {
+	// DeleteMany deletes keys, which may not exist, and returns the error of every key it could not delete.
+	DeleteMany(ctx context.Context, keys []string) (map[string]error, error)
+}
+
+// IsNotFound reports whether err means that the object does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+func notFound(key string) error {
+	return fmt.Errorf("%w: %s", ErrNotFound, key)
+}
+
+// validateKey rejects the names S3 rejects and the ones that could escape a filesystem root.
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return fmt.Errorf("invalid object name: %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid object name: %q", key)
+		}
+	}
+	return nil
+}
+
+func joinURL(baseURL string, key string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if key == "" {
+		return baseURL
+	}
+	return baseURL + "/" + key
+}
+
+// newInfo returns the stored Info of a local object before its size, ETag and modification time are known.
+func newInfo(key string, opts PutOptions) Info {
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+	return Info{
+		Key:                key,
+		ContentType:        contentType,
+		ContentDisposition: opts.ContentDisposition,
+		CacheControl:       opts.CacheControl,
+		Metadata:           lowerKeys(opts.Metadata),
+	}
+}
+
+func lowerKeys(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	result := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		result[strings.ToLower(key)] = value
+	}
+	return result
+}
+
+// listKeys iterates the sorted keys under prefix after startAfter, skipping the ones deleted while listing.
+func listKeys(ctx context.Context, keys []string, prefix string, startAfter string, stat func(key string) (Info, error)) iter.Seq2[Info, error] {
+	return func(yield func(Info, error) bool) {
+		for _, key := range keys {
+			if !strings.HasPrefix(key, prefix) || key <= startAfter {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				yield(Info{}, err)
+				return
+			}
+			info, err := stat(key)
+			if IsNotFound(err) {
+				continue
+			}
+			if !yield(info, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sizedReader fails with io.ErrUnexpectedEOF when the body ends before the declared size.
+type sizedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func newSizedReader(body io.Reader, size int64) io.Reader {
+	if size < 0 {
+		return body
+	}
+	return &sizedReader{reader: io.LimitReader(body, size), remaining: size}
+}
+
+func (r *sizedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if errors.Is(err, io.EOF) && r.remaining > 0 {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
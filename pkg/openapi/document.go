@@ -0,0 +1,283 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+const Version = "3.1.0"
+
+const bearerScheme = "bearerAuth"
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	Responses       map[string]*Response       `json:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+}
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	XRoles      []string              `json:"x-roles,omitempty"`
+	XStates     []string              `json:"x-states,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+	Explode  *bool   `json:"explode,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Auth describes the guard of an operation.
+type Auth struct {
+	Roles  []string
+	States []string
+}
+
+// Route is the input used to describe an operation.
+type Route struct {
+	Method      string
+	Pattern     string
+	Summary     string
+	Description string
+	Tags        []string
+	Request     reflect.Type
+	// Responses maps a status code to the response body type. A nil type documents an empty body.
+	Responses map[int]reflect.Type
+	Auth      *Auth
+}
+
+type Generator struct {
+	info     Info
+	servers  []Server
+	schemas  *schemaRegistry
+	paths    map[string]*PathItem
+	usesAuth bool
+}
+
+func NewGenerator(info Info, servers ...Server) *Generator {
+	g := &Generator{
+		info:    info,
+		servers: servers,
+		schemas: newSchemaRegistry(),
+		paths:   map[string]*PathItem{},
+	}
+	g.schemas.schemaFor(reflect.TypeOf(pkgErrors.ErrorResponse{}))
+	return g
+}
+
+var pathParamRegex = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+func (g *Generator) Add(route Route) {
+	path := pathParamRegex.ReplaceAllString(route.Pattern, "{$1}")
+	path = strings.TrimSuffix(path, "/*")
+	if path == "" {
+		path = "/"
+	}
+
+	op := &Operation{
+		Tags:        route.Tags,
+		Summary:     route.Summary,
+		Description: route.Description,
+		OperationID: operationID(route.Method, path),
+		Responses:   map[string]*Response{},
+	}
+
+	declared := map[string]bool{}
+	for _, match := range pathParamRegex.FindAllStringSubmatch(route.Pattern, -1) {
+		declared[match[1]] = true
+	}
+
+	if route.Request != nil {
+		params, body := g.schemas.requestSchemas(route.Request)
+		for _, param := range params {
+			if param.In == "path" {
+				delete(declared, param.Name)
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+		if body != nil {
+			op.RequestBody = body
+		}
+	}
+
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+
+	for status, typ := range route.Responses {
+		resp := &Response{Description: http.StatusText(status)}
+		if typ != nil {
+			resp.Content = map[string]*MediaType{"application/json": {Schema: g.schemas.schemaFor(typ)}}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+	if len(route.Responses) == 0 {
+		op.Responses["200"] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+
+	errorRef := &Response{Ref: "#/components/responses/Error"}
+	if op.RequestBody != nil || len(op.Parameters) > 0 {
+		op.Responses["400"] = errorRef
+		op.Responses["422"] = errorRef
+	}
+	if route.Auth != nil {
+		g.usesAuth = true
+		op.Security = []map[string][]string{{bearerScheme: {}}}
+		op.XRoles = route.Auth.Roles
+		op.XStates = route.Auth.States
+		op.Responses["401"] = errorRef
+	}
+	op.Responses["500"] = errorRef
+
+	item, ok := g.paths[path]
+	if !ok {
+		item = &PathItem{}
+		g.paths[path] = item
+	}
+	switch strings.ToUpper(route.Method) {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodOptions:
+		item.Options = op
+	case http.MethodHead:
+		item.Head = op
+	case http.MethodPatch:
+		item.Patch = op
+	}
+}
+
+func (g *Generator) Document() *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    g.info,
+		Servers: g.servers,
+		Paths:   g.paths,
+		Components: Components{
+			Schemas: g.schemas.components,
+			Responses: map[string]*Response{
+				"Error": {
+					Description: "Error response following RFC 7807",
+					Content: map[string]*MediaType{
+						"application/json": {Schema: g.schemas.schemaFor(reflect.TypeOf(pkgErrors.ErrorResponse{}))},
+					},
+				},
+			},
+		},
+	}
+	if g.usesAuth {
+		doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+			bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}
+	}
+	return doc
+}
+
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+func (d *Document) YAML() ([]byte, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	writeYAML(&sb, value, 0)
+	return []byte(sb.String()), nil
+}
+
+func operationID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, part := range strings.Split(path, "/") {
+		part = strings.Trim(part, "{}")
+		if part == "" {
+			continue
+		}
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,387 @@
+package openapi
+
+import (
+	"encoding"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	fileHeaderType    = reflect.TypeOf(multipart.FileHeader{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type schemaRegistry struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// schemaFor returns a $ref for named structs and an inline schema for everything else.
+func (s *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if schema := scalarSchema(t); schema != nil {
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t, nil)
+		}
+		name, ok := s.names[t]
+		if !ok {
+			name = s.componentName(t)
+			s.names[t] = name
+			s.components[name] = &Schema{}
+			*s.components[name] = *s.structSchema(t, nil)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	case reflect.Interface:
+		return &Schema{}
+	}
+	return &Schema{}
+}
+
+func scalarSchema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "duration in nanoseconds"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	}
+
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+	return nil
+}
+
+// structSchema builds an object schema. When skip is not nil the fields it returns true for are left out.
+func (s *schemaRegistry) structSchema(t reflect.Type, skip func(field reflect.StructField) bool) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || (skip != nil && skip(field)) {
+			continue
+		}
+
+		name, ignored := jsonName(field)
+		if ignored {
+			continue
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := s.structSchema(ft, skip)
+				for k, v := range embedded.Properties {
+					schema.Properties[k] = v
+				}
+				schema.Required = append(schema.Required, embedded.Required...)
+				continue
+			}
+		}
+
+		fieldSchema := s.fieldSchema(field)
+		schema.Properties[name] = fieldSchema
+
+		if _, ok := parseValidateTag(field.Tag.Get("validate"))["required"]; ok {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+func (s *schemaRegistry) fieldSchema(field reflect.StructField) *Schema {
+	schema := s.schemaFor(field.Type)
+	rules := parseValidateTag(field.Tag.Get("validate"))
+	if len(rules) == 0 && field.Type.Kind() != reflect.Ptr {
+		return schema
+	}
+
+	// $ref siblings are allowed in 3.1, but keep constraints on a copy to not modify shared components.
+	copied := *schema
+	schema = &copied
+
+	if field.Type.Kind() == reflect.Ptr && schema.Ref == "" {
+		if typ, ok := schema.Type.(string); ok {
+			schema.Type = []string{typ, "null"}
+		}
+	}
+
+	ft := field.Type
+	for ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	applyRules(schema, ft.Kind(), rules)
+	return schema
+}
+
+func applyRules(schema *Schema, kind reflect.Kind, rules map[string]string) {
+	isString := kind == reflect.String
+	isArray := kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+
+	for rule, param := range rules {
+		switch rule {
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case isString:
+				setLength(&schema.MinLength, &schema.MaxLength, rule, int(n))
+			case isArray:
+				setLength(&schema.MinItems, &schema.MaxItems, rule, int(n))
+			default:
+				switch rule {
+				case "min", "gte":
+					schema.Minimum = &n
+				case "max", "lte":
+					schema.Maximum = &n
+				case "gt":
+					schema.ExclusiveMinimum = &n
+				case "lt":
+					schema.ExclusiveMaximum = &n
+				case "len":
+					schema.Minimum, schema.Maximum = &n, &n
+				}
+			}
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				if isString {
+					schema.Enum = append(schema.Enum, v)
+				} else if n, err := strconv.ParseFloat(v, 64); err == nil {
+					schema.Enum = append(schema.Enum, n)
+				}
+			}
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "datetime":
+			schema.Format = "date-time"
+		case "iso_duration":
+			schema.Format = "duration"
+		case "ip", "ipv4":
+			schema.Format = "ipv4"
+		case "ipv6":
+			schema.Format = "ipv6"
+		case "alpha":
+			schema.Pattern = "^[a-zA-Z]+$"
+		case "alphanum":
+			schema.Pattern = "^[a-zA-Z0-9]+$"
+		case "numeric":
+			schema.Pattern = "^[-+]?[0-9]+(?:\\.[0-9]+)?$"
+		}
+	}
+}
+
+func setLength(min, max **int, rule string, n int) {
+	switch rule {
+	case "min", "gte":
+		*min = &n
+	case "max", "lte":
+		*max = &n
+	case "gt":
+		v := n + 1
+		*min = &v
+	case "lt":
+		v := n - 1
+		*max = &v
+	case "len":
+		*min, *max = &n, &n
+	}
+}
+
+// parseValidateTag ignores dive and everything after it, since those rules apply to the elements.
+func parseValidateTag(tag string) map[string]string {
+	if tag == "" {
+		return nil
+	}
+	rules := map[string]string{}
+	for _, part := range strings.Split(tag, ",") {
+		if part == "dive" {
+			break
+		}
+		name, param, _ := strings.Cut(part, "=")
+		rules[name] = param
+	}
+	return rules
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func (s *schemaRegistry) componentName(t reflect.Type) string {
+	name := invalidNameChars.ReplaceAllString(t.Name(), "_")
+	name = strings.Trim(name, "_")
+	if _, taken := s.components[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	if idx := strings.LastIndex(pkg, "/"); idx >= 0 {
+		pkg = pkg[idx+1:]
+	}
+	base := invalidNameChars.ReplaceAllString(pkg, "_") + "." + name
+	candidate := base
+	for i := 2; ; i++ {
+		if _, taken := s.components[candidate]; !taken {
+			return candidate
+		}
+		candidate = base + strconv.Itoa(i)
+	}
+}
+
+// requestSchemas splits a request struct bound by router.Bind into parameters and body.
+func (s *schemaRegistry) requestSchemas(t reflect.Type) ([]*Parameter, *RequestBody) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: s.schemaFor(t)}}}
+	}
+
+	var params []*Parameter
+	form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	hasTagged := false
+
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				collect(field.Type)
+				continue
+			}
+			rules := parseValidateTag(field.Tag.Get("validate"))
+			_, required := rules["required"]
+			for _, in := range []string{"path", "query", "header"} {
+				name := field.Tag.Get(in)
+				if name == "" {
+					continue
+				}
+				hasTagged = true
+				param := &Parameter{Name: name, In: in, Required: required || in == "path", Schema: s.fieldSchema(field)}
+				if in == "query" && (field.Type.Kind() == reflect.Slice) {
+					explode := true
+					param.Explode = &explode
+				}
+				params = append(params, param)
+			}
+			if name := field.Tag.Get("form"); name != "" {
+				hasTagged = true
+				form.Properties[name] = s.fieldSchema(field)
+				if required {
+					form.Required = append(form.Required, name)
+				}
+			}
+		}
+	}
+	collect(t)
+
+	if len(form.Properties) > 0 {
+		return params, &RequestBody{Required: true, Content: map[string]*MediaType{"multipart/form-data": {Schema: form}}}
+	}
+
+	var body *Schema
+	if hasTagged {
+		body = s.structSchema(t, func(field reflect.StructField) bool {
+			return field.Tag.Get("path") != "" || field.Tag.Get("query") != "" || field.Tag.Get("header") != "" || field.Tag.Get("form") != ""
+		})
+		if len(body.Properties) == 0 {
+			return params, nil
+		}
+	} else {
+		body = s.schemaFor(t)
+	}
+
+	return params, &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: body}}}
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// DefaultSwaggerUIAssetsURL is the CDN the Swagger UI assets are loaded from by default.
+const DefaultSwaggerUIAssetsURL = "https://unpkg.com/swagger-ui-dist@5"
+
+// SwaggerUI renders a Swagger UI page for the spec served at SpecURL.
+type SwaggerUI struct {
+	Title   string
+	SpecURL string
+	// AssetsURL is where swagger-ui.css and swagger-ui-bundle.js are loaded from. Defaults to
+	// DefaultSwaggerUIAssetsURL, point it to a copy of swagger-ui-dist to serve the docs offline.
+	AssetsURL string
+	// Nonce allows the inline script under ContentSecurityPolicy.
+	Nonce string
+}
+
+// SwaggerUIHTML renders a Swagger UI page for the spec served at specURL. The assets are loaded from a CDN.
+func SwaggerUIHTML(title string, specURL string) string {
+	return SwaggerUI{Title: title, SpecURL: specURL}.HTML()
+}
+
+func (s SwaggerUI) HTML() string {
+	assets := strings.TrimSuffix(s.assetsURL(), "/")
+	nonce := ""
+	if s.Nonce != "" {
+		nonce = fmt.Sprintf(` nonce="%s"`, html.EscapeString(s.Nonce))
+	}
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>%s</title>
+  <link rel="stylesheet" href="%s/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="%s/swagger-ui-bundle.js" crossorigin></script>
+  <script%s>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: %q, dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`, html.EscapeString(s.Title), html.EscapeString(assets), html.EscapeString(assets), nonce, s.SpecURL)
+}
+
+// ContentSecurityPolicy allows the page to load its assets and the spec and nothing else. The
+// inline styles are set by Swagger UI itself.
+func (s SwaggerUI) ContentSecurityPolicy() string {
+	source := "'self'"
+	if u, err := url.Parse(s.assetsURL()); err == nil && u.Scheme != "" && u.Host != "" {
+		source = u.Scheme + "://" + u.Host
+	}
+	script := source
+	if s.Nonce != "" {
+		script += " 'nonce-" + s.Nonce + "'"
+	}
+	return fmt.Sprintf("default-src 'none'; script-src %s; style-src %s 'unsafe-inline'; img-src 'self' data: %s; connect-src 'self'; frame-ancestors 'none'",
+		script, source, source)
+}
+
+func (s SwaggerUI) assetsURL() string {
+	if s.AssetsURL == "" {
+		return DefaultSwaggerUIAssetsURL
+	}
+	return s.AssetsURL
+}
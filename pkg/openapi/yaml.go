@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// writeYAML emits the values produced by decoding JSON, which is all the document needs.
+func writeYAML(sb *strings.Builder, value any, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			sb.WriteString(pad + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteString(pad + yamlString(k) + ":")
+			writeYAMLChild(sb, v[k], indent)
+		}
+	case []any:
+		if len(v) == 0 {
+			sb.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok && len(m) > 0 {
+				// Render the map one level deeper and put the dash in place of the first indentation.
+				var child strings.Builder
+				writeYAML(&child, m, indent+1)
+				sb.WriteString(pad + "- " + strings.TrimPrefix(child.String(), pad+"  "))
+				continue
+			}
+			sb.WriteString(pad + "-")
+			writeYAMLChild(sb, item, indent)
+		}
+	default:
+		sb.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+func writeYAMLChild(sb *strings.Builder, value any, indent int) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			sb.WriteString(" {}\n")
+			return
+		}
+		sb.WriteString("\n")
+		writeYAML(sb, v, indent+1)
+	case []any:
+		if len(v) == 0 {
+			sb.WriteString(" []\n")
+			return
+		}
+		sb.WriteString("\n")
+		writeYAML(sb, v, indent+1)
+	default:
+		sb.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return yamlString(v)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func yamlString(s string) string {
+	if s == "" {
+		return `""`
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "null", "yes", "no", "on", "off", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	if strings.ContainsAny(s, ":#{}[]&*!|>'\"%@`,?\n\t\\") || strings.HasPrefix(s, "-") || strings.HasPrefix(s, " ") || strings.HasSuffix(s, " ") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package patch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append", `{"foo":[1]}`, `[{"op":"add","path":"/foo/-","value":2}]`, `{"foo":[1,2]}`},
+		{"add nested member", `{"a":{"b":[{"c":1}]}}`, `[{"op":"add","path":"/a/b/0/d","value":2}]`, `{"a":{"b":[{"c":1,"d":2}]}}`},
+		{"add replaces member", `{"foo":1}`, `[{"op":"add","path":"/foo","value":2}]`, `{"foo":2}`},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"replace array element", `{"a":[1,2,3]}`, `[{"op":"replace","path":"/a/1","value":9}]`, `{"a":[1,9,3]}`},
+		{"replace document", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+		{"move", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"copy is independent", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/m~0n","value":3}]`, `{"m~n":3}`},
+		{"null value", `{"a":1}`, `[{"op":"add","path":"/b","value":null}]`, `{"a":1,"b":null}`},
+		{"empty patch", `{"a":1}`, `[]`, `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if !jsonEqual(t, got, []byte(tt.want)) {
+				t.Errorf("Apply = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name, doc, patch string
+		wantErr          error
+	}{
+		{name: "test fails", doc: `{"a":1}`, patch: `[{"op":"test","path":"/a","value":2}]`, wantErr: ErrTestFailed},
+		{name: "test type differs", doc: `{"a":"1"}`, patch: `[{"op":"test","path":"/a","value":1}]`, wantErr: ErrTestFailed},
+		{name: "missing path", doc: `{"a":1}`, patch: `[{"op":"remove","path":"/b"}]`},
+		{name: "missing parent", doc: `{"a":1}`, patch: `[{"op":"add","path":"/b/c","value":1}]`},
+		{name: "index out of range", doc: `{"a":[1]}`, patch: `[{"op":"add","path":"/a/2","value":1}]`},
+		{name: "leading zero index", doc: `{"a":[1,2]}`, patch: `[{"op":"remove","path":"/a/01"}]`},
+		{name: "negative index", doc: `{"a":[1,2]}`, patch: `[{"op":"remove","path":"/a/-1"}]`},
+		{name: "invalid pointer", doc: `{"a":1}`, patch: `[{"op":"remove","path":"a"}]`},
+		{name: "missing value", doc: `{"a":1}`, patch: `[{"op":"add","path":"/b"}]`},
+		{name: "unknown op", doc: `{"a":1}`, patch: `[{"op":"merge","path":"/a","value":1}]`},
+		{name: "remove document", doc: `{"a":1}`, patch: `[{"op":"remove","path":""}]`},
+		{name: "move into child", doc: `{"a":{"b":1}}`, patch: `[{"op":"move","from":"/a","path":"/a/c"}]`},
+		{name: "not an array", doc: `{"a":1}`, patch: `{"op":"remove","path":"/a"}`},
+		{name: "invalid document", doc: `{"a":`, patch: `[]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if err == nil {
+				t.Fatal("Apply succeeded")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// A failing operation leaves no partial result behind.
+func TestApplyAtomic(t *testing.T) {
+	doc := []byte(`{"a":1}`)
+	if _, err := Apply(doc, []byte(`[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`)); !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("err = %v", err)
+	}
+	if string(doc) != `{"a":1}` {
+		t.Errorf("doc modified: %s", doc)
+	}
+}
@@ -0,0 +1,62 @@
+package patch
+
+import "testing"
+
+// jsonEqual compares two JSON documents ignoring formatting and key order.
+func jsonEqual(t *testing.T, got, want []byte) bool {
+	t.Helper()
+	a, err := decode(got)
+	if err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	b, err := decode(want)
+	if err != nil {
+		t.Fatalf("invalid JSON %s: %v", want, err)
+	}
+	return equal(a, b)
+}
+
+// The examples of RFC 7396 appendix A.
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		// Large integers are not rounded through float64.
+		{`{"id":9007199254740993}`, `{"n":1}`, `{"id":9007199254740993,"n":1}`},
+	}
+
+	for _, tt := range tests {
+		got, err := Merge([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("Merge(%s, %s): %v", tt.doc, tt.patch, err)
+			continue
+		}
+		if !jsonEqual(t, got, []byte(tt.want)) {
+			t.Errorf("Merge(%s, %s) = %s, want %s", tt.doc, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestMergeInvalid(t *testing.T) {
+	if _, err := Merge([]byte(`{"a":`), []byte(`{}`)); err == nil {
+		t.Error("Merge of an invalid document succeeded")
+	}
+	if _, err := Merge([]byte(`{}`), []byte(`{"a"}`)); err == nil {
+		t.Error("Merge of an invalid patch succeeded")
+	}
+}
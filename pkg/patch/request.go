@@ -0,0 +1,155 @@
+package patch
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/router"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/validation"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// ApplyRequest applies the merge patch or JSON patch in the request body onto current, picking the
+// format from the Content-Type. It returns the patched copy, the changed field paths (as in
+// "album.title") and validates the result like ParseBody. current is never modified. The fields
+// JSON does not carry, unexported or tagged json:"-", keep the values of current in struct fields
+// and embedded structs, but not behind pointers, maps or slices, which the patched JSON replaces.
+// opts are applied when reading the body, e.g. router.MaxBodySize.
+func ApplyRequest[T any](r *http.Request, current T, opts ...router.DecodeOption) (T, []string, error) {
+	var result T
+
+	opts = append(opts, router.RequireContentType(MergePatchContentType, JSONPatchContentType))
+	var body json.RawMessage
+	if err := router.DecodeJSON(r, &body, opts...); err != nil {
+		return result, nil, err
+	}
+
+	before, err := json.Marshal(current)
+	if err != nil {
+		return result, nil, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var after []byte
+	switch mediaType {
+	case MergePatchContentType:
+		after, err = Merge(before, body)
+	case JSONPatchContentType:
+		after, err = Apply(before, body)
+	}
+	if err != nil {
+		if errors.Is(err, ErrTestFailed) {
+			return result, nil, &pkgErrors.AppError{
+				Code:     "PATCH_TEST_FAILED",
+				Title:    "Conflict",
+				Message:  err.Error(),
+				HTTPCode: http.StatusConflict,
+			}
+		}
+		return result, nil, pkgErrors.NewUnprocessableEntityError(fmt.Sprintf("Could not apply patch: %s", err.Error()))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(after))
+	decoder.DisallowUnknownFields()
+	var patched T
+	if err := decoder.Decode(&patched); err != nil {
+		return result, nil, pkgErrors.NewUnprocessableEntityError(fmt.Sprintf("Patched resource is invalid: %s", err.Error()))
+	}
+
+	changed, err := Changed(before, after)
+	if err != nil {
+		return result, nil, err
+	}
+
+	result = current
+	if v := reflect.ValueOf(&result).Elem(); v.Kind() == reflect.Struct {
+		copyJSONFields(v, reflect.ValueOf(patched))
+	} else {
+		result = patched
+	}
+
+	if err := validation.Struct(result); err != nil {
+		return result, changed, err
+	}
+	return result, changed, nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// copyJSONFields sets the fields of dst that JSON carries to the ones of src. Struct fields are
+// copied field by field unless they decode themselves, like time.Time.
+func copyJSONFields(dst, src reflect.Value) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		pointer := reflect.PointerTo(field.Type)
+		if field.Type.Kind() == reflect.Struct && !pointer.Implements(jsonUnmarshalerType) && !pointer.Implements(textUnmarshalerType) {
+			copyJSONFields(dst.Field(i), src.Field(i))
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+	}
+}
+
+// Changed returns the paths of the fields that differ between two JSON documents, sorted.
+// Objects are compared field by field; arrays and scalars are reported as a whole.
+func Changed(before, after []byte) ([]string, error) {
+	a, err := decode(before)
+	if err != nil {
+		return nil, err
+	}
+	b, err := decode(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	diff(a, b, "", &changed)
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func diff(a, b any, path string, changed *[]string) {
+	x, okA := a.(map[string]any)
+	y, okB := b.(map[string]any)
+	if !okA || !okB {
+		if !equal(a, b) && path != "" {
+			*changed = append(*changed, path)
+		}
+		return
+	}
+
+	for key, value := range x {
+		diff(value, y[key], joinPath(path, key), changed)
+	}
+	for key, value := range y {
+		if _, ok := x[key]; !ok {
+			diff(nil, value, joinPath(path, key), changed)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,143 @@
+package patch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+type album struct {
+	Title string   `json:"title" validate:"required"`
+	Year  int      `json:"year"`
+	Tags  []string `json:"tags"`
+	Label struct {
+		Name string `json:"name"`
+	} `json:"label"`
+}
+
+func TestApplyRequest(t *testing.T) {
+	current := album{Title: "First", Year: 2020, Tags: []string{"rock"}}
+	current.Label.Name = "Indie"
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantTitle   string
+		wantChanged []string
+		wantStatus  int
+	}{
+		{
+			name:        "merge patch",
+			contentType: MergePatchContentType,
+			body:        `{"title":"Second","label":{"name":"Major"}}`,
+			wantTitle:   "Second",
+			wantChanged: []string{"label.name", "title"},
+		},
+		{
+			name:        "JSON patch",
+			contentType: JSONPatchContentType,
+			body:        `[{"op":"test","path":"/year","value":2020},{"op":"add","path":"/tags/-","value":"pop"}]`,
+			wantTitle:   "First",
+			wantChanged: []string{"tags"},
+		},
+		{
+			name:        "content type parameters",
+			contentType: MergePatchContentType + "; charset=utf-8",
+			body:        `{}`,
+			wantTitle:   "First",
+		},
+		{name: "test fails", contentType: JSONPatchContentType, body: `[{"op":"test","path":"/year","value":1999}]`, wantStatus: http.StatusConflict},
+		{name: "unknown field", contentType: MergePatchContentType, body: `{"genre":"jazz"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "wrong type", contentType: MergePatchContentType, body: `{"year":"new"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "invalid operation", contentType: JSONPatchContentType, body: `[{"op":"remove","path":"/missing"}]`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "validation", contentType: MergePatchContentType, body: `{"title":null}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "plain JSON", contentType: "application/json", body: `{"title":"Second"}`, wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/albums/1", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			patched, changed, err := ApplyRequest(r, current)
+			if tt.wantStatus != 0 {
+				var appErr *pkgErrors.AppError
+				if !errors.As(err, &appErr) || appErr.HTTPCode != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyRequest: %v", err)
+			}
+			if patched.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", patched.Title, tt.wantTitle)
+			}
+			if !slices.Equal(changed, tt.wantChanged) {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+
+	if current.Title != "First" || len(current.Tags) != 1 || current.Label.Name != "Indie" {
+		t.Errorf("current was modified: %+v", current)
+	}
+}
+
+type account struct {
+	ID           int    `json:"-"`
+	Name         string `json:"name"`
+	passwordHash string
+	Profile      struct {
+		Bio      string `json:"bio"`
+		Verified bool   `json:"-"`
+	} `json:"profile"`
+	Joined time.Time         `json:"joined"`
+	Links  map[string]string `json:"links"`
+}
+
+func TestApplyRequestHiddenFields(t *testing.T) {
+	current := account{ID: 7, Name: "Ana", passwordHash: "hash", Joined: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Links: map[string]string{"web": "a", "x": "b"}}
+	current.Profile.Bio = "old"
+	current.Profile.Verified = true
+
+	r := httptest.NewRequest(http.MethodPatch, "/accounts/7", strings.NewReader(`{"name":"Ana B","profile":{"bio":"new"},"joined":"2025-03-04T00:00:00Z","links":{"x":null}}`))
+	r.Header.Set("Content-Type", MergePatchContentType)
+	got, _, err := ApplyRequest(r, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ID != 7 || got.passwordHash != "hash" || !got.Profile.Verified {
+		t.Errorf("hidden fields lost: %+v", got)
+	}
+	if got.Name != "Ana B" || got.Profile.Bio != "new" || !got.Joined.Equal(time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("patch not applied: %+v", got)
+	}
+	if len(got.Links) != 1 || got.Links["web"] != "a" {
+		t.Errorf("links = %v, want x removed", got.Links)
+	}
+	if current.Name != "Ana" || current.Profile.Bio != "old" || len(current.Links) != 2 {
+		t.Errorf("current modified: %+v", current)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	changed, err := Changed(
+		[]byte(`{"a":1,"b":{"c":1,"d":[1]},"e":1.0}`),
+		[]byte(`{"a":1,"b":{"c":2,"d":[1,2]},"e":1,"f":null}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b.c", "b.d"}; !slices.Equal(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+}
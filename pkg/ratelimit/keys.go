@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/ctx"
+	"github.com/go-chi/chi/v5"
+)
+
+// KeyFunc returns the identity a request is limited by. An empty key skips limiting.
+type KeyFunc func(r *http.Request) string
+
+func ByUserID() KeyFunc {
+	return func(r *http.Request) string {
+		userID, err := ctx.GetUserID(r.Context())
+		if err != nil {
+			return ""
+		}
+		return "user:" + userID.String()
+	}
+}
+
+func ByIP(resolver *IPResolver) KeyFunc {
+	return func(r *http.Request) string {
+		ip := resolver.ClientIP(r)
+		if ip == "" {
+			return ""
+		}
+		return "ip:" + ip
+	}
+}
+
+func ByAPIKey(header string) KeyFunc {
+	return func(r *http.Request) string {
+		key := r.Header.Get(header)
+		if key == "" {
+			return ""
+		}
+		return "apikey:" + key
+	}
+}
+
+func ByRoute() KeyFunc {
+	return func(r *http.Request) string {
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				return "route:" + r.Method + " " + pattern
+			}
+		}
+		return "route:" + r.Method + " " + r.URL.Path
+	}
+}
+
+// FirstOf uses the first key function that returns a non empty key.
+func FirstOf(fns ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		for _, fn := range fns {
+			if key := fn(r); key != "" {
+				return key
+			}
+		}
+		return ""
+	}
+}
+
+// Compose joins every key, for example to limit a user on a specific route.
+func Compose(fns ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		parts := make([]string, 0, len(fns))
+		for _, fn := range fns {
+			key := fn(r)
+			if key == "" {
+				return ""
+			}
+			parts = append(parts, key)
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+type IPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewIPResolver only honors X-Forwarded-For when the request comes from one of the trusted proxies.
+func NewIPResolver(trustedProxies ...string) (*IPResolver, error) {
+	resolver := &IPResolver{}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if strings.Contains(proxy, ":") {
+				proxy += "/128"
+			} else {
+				proxy += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+func (res *IPResolver) ClientIP(r *http.Request) string {
+	remote := remoteIP(r.RemoteAddr)
+	if res == nil || !res.isTrusted(remote) {
+		return remote
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			break
+		}
+		if !res.isTrusted(ip.String()) {
+			return ip.String()
+		}
+	}
+
+	return remote
+}
+
+func (res *IPResolver) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range res.trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return host
+}
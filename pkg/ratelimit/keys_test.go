@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	resolver, err := NewIPResolver("10.0.0.0/8", "192.168.1.1", "::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		resolver   *IPResolver
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "no proxy", resolver: resolver, remoteAddr: "203.0.113.7:1234", want: "203.0.113.7"},
+		{name: "untrusted proxy", resolver: resolver, remoteAddr: "203.0.113.7:1234", forwarded: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "trusted proxy", resolver: resolver, remoteAddr: "10.0.0.2:1234", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed hops", resolver: resolver, remoteAddr: "10.0.0.2:1234", forwarded: []string{"1.1.1.1, 198.51.100.1, 10.0.0.3"}, want: "198.51.100.1"},
+		{name: "several headers", resolver: resolver, remoteAddr: "192.168.1.1:80", forwarded: []string{"1.1.1.1", "198.51.100.1"}, want: "198.51.100.1"},
+		{name: "invalid hop", resolver: resolver, remoteAddr: "10.0.0.2:1234", forwarded: []string{"198.51.100.1, garbage"}, want: "10.0.0.2"},
+		{name: "only proxies", resolver: resolver, remoteAddr: "10.0.0.2:1234", forwarded: []string{"10.0.0.3"}, want: "10.0.0.2"},
+		{name: "IPv6 proxy", resolver: resolver, remoteAddr: "[::1]:1234", forwarded: []string{"2001:db8::1"}, want: "2001:db8::1"},
+		{name: "nil resolver", remoteAddr: "10.0.0.2:1234", forwarded: []string{"198.51.100.1"}, want: "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if got := tt.resolver.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewIPResolver("not an ip"); err == nil {
+		t.Error("NewIPResolver with an invalid proxy succeeded")
+	}
+}
+
+func TestKeyFuncs(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/songs", nil)
+	r.RemoteAddr = "203.0.113.7:1234"
+	r.Header.Set("X-API-Key", "secret")
+
+	tests := []struct {
+		name string
+		fn   KeyFunc
+		want string
+	}{
+		{"user without authentication", ByUserID(), ""},
+		{"api key", ByAPIKey("X-API-Key"), "apikey:secret"},
+		{"route without chi", ByRoute(), "route:POST /songs"},
+		{"first of", FirstOf(ByUserID(), ByAPIKey("X-Missing"), ByIP(nil)), "ip:203.0.113.7"},
+		{"compose", Compose(ByRoute(), ByIP(nil)), "route:POST /songs|ip:203.0.113.7"},
+		{"compose with an empty key", Compose(ByRoute(), ByUserID()), ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.fn(r); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
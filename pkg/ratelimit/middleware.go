@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+type Config struct {
+	// Name prefixes the keys so several limiters can share one store.
+	Name    string
+	Limit   Limit
+	KeyFunc KeyFunc
+	Store   Store
+	// FailOpen lets requests through when the store returns an error.
+	FailOpen bool
+}
+
+type Limiter struct {
+	config Config
+}
+
+func NewLimiter(config Config) (*Limiter, error) {
+	if config.Limit.Requests <= 0 || config.Limit.Window <= 0 {
+		return nil, errors.New("limit requests and window must be positive")
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = ByIP(nil)
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	return &Limiter{config: config}, nil
+}
+
+// Middleware is meant to be registered with router.UseErrorMiddleware so the 429 goes through the ErrorHandler.
+func (l *Limiter) Middleware(w http.ResponseWriter, r *http.Request) error {
+	key := l.config.KeyFunc(r)
+	if key == "" {
+		return nil
+	}
+	if l.config.Name != "" {
+		key = l.config.Name + ":" + key
+	}
+
+	result, err := l.config.Store.Allow(r.Context(), key, l.config.Limit)
+	if err != nil {
+		if l.config.FailOpen {
+			return nil
+		}
+		return err
+	}
+
+	header := w.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+	if !result.Allowed {
+		header.Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
+		return pkgErrors.NewTooManyRequestsError("Rate limit exceeded, try again later")
+	}
+
+	return nil
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+type failingStore struct{}
+
+func (failingStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestMiddleware(t *testing.T) {
+	limiter, err := NewLimiter(Config{Name: "songs", Limit: Limit{Requests: 1, Window: time.Minute}, KeyFunc: ByAPIKey("X-API-Key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(key string) (*httptest.ResponseRecorder, error) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if key != "" {
+			r.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		return w, limiter.Middleware(w, r)
+	}
+
+	w, err := request("a")
+	if err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	if w.Header().Get("RateLimit-Limit") != "1" || w.Header().Get("RateLimit-Remaining") != "0" || w.Header().Get("RateLimit-Reset") != "60" {
+		t.Errorf("headers = %v", w.Header())
+	}
+
+	w, err = request("a")
+	var appErr *pkgErrors.AppError
+	if !errors.As(err, &appErr) || appErr.HTTPCode != http.StatusTooManyRequests {
+		t.Fatalf("second request err = %v, want 429", err)
+	}
+	if w.Header().Get("Retry-After") != "60" {
+		t.Errorf("Retry-After = %q", w.Header().Get("Retry-After"))
+	}
+
+	for _, key := range []string{"b", "", ""} {
+		if _, err := request(key); err != nil {
+			t.Errorf("request with key %q: %v", key, err)
+		}
+	}
+}
+
+func TestMiddlewareStoreErrors(t *testing.T) {
+	for _, failOpen := range []bool{false, true} {
+		limiter, err := NewLimiter(Config{Limit: Limit{Requests: 1, Window: time.Minute}, Store: failingStore{}, FailOpen: failOpen})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = limiter.Middleware(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		if (err == nil) != failOpen {
+			t.Errorf("FailOpen %t: err = %v", failOpen, err)
+		}
+	}
+
+	if _, err := NewLimiter(Config{Limit: Limit{Requests: 0, Window: time.Minute}}); err == nil {
+		t.Error("NewLimiter without requests succeeded")
+	}
+}
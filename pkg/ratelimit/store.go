@@ -0,0 +1,172 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type Algorithm int
+
+const (
+	TokenBucket Algorithm = iota
+	SlidingWindow
+)
+
+type Limit struct {
+	Algorithm Algorithm
+	// Requests allowed per Window.
+	Requests int
+	Window   time.Duration
+	// Burst is the token bucket capacity. Defaults to Requests.
+	Burst int
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucketState struct {
+	tokens   float64
+	last     time.Time
+	expireAt time.Time
+}
+
+type windowState struct {
+	start    time.Time
+	current  int
+	previous int
+	expireAt time.Time
+}
+
+type MemoryStore struct {
+	mutex       sync.Mutex
+	buckets     map[string]*bucketState
+	windows     map[string]*windowState
+	now         func() time.Time
+	lastCleanup time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucketState{},
+		windows: map[string]*windowState{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	s.cleanup(now)
+
+	if limit.Algorithm == SlidingWindow {
+		return s.slidingWindow(key, limit, now), nil
+	}
+	return s.tokenBucket(key, limit, now), nil
+}
+
+func (s *MemoryStore) tokenBucket(key string, limit Limit, now time.Time) Result {
+	capacity := float64(limit.Burst)
+	if capacity <= 0 {
+		capacity = float64(limit.Requests)
+	}
+	rate := float64(limit.Requests) / limit.Window.Seconds()
+
+	state, ok := s.buckets[key]
+	if !ok {
+		state = &bucketState{tokens: capacity, last: now}
+		s.buckets[key] = state
+	}
+
+	state.tokens = math.Min(capacity, state.tokens+now.Sub(state.last).Seconds()*rate)
+	state.last = now
+
+	result := Result{Limit: int(capacity)}
+	if state.tokens >= 1 {
+		state.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - state.tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(state.tokens))
+	result.ResetAfter = secondsToDuration((capacity - state.tokens) / rate)
+	state.expireAt = now.Add(result.ResetAfter)
+	return result
+}
+
+func (s *MemoryStore) slidingWindow(key string, limit Limit, now time.Time) Result {
+	window := limit.Window
+	start := now.Truncate(window)
+
+	state, ok := s.windows[key]
+	if !ok {
+		state = &windowState{start: start}
+		s.windows[key] = state
+	}
+
+	switch elapsed := start.Sub(state.start); {
+	case elapsed == window:
+		state.previous = state.current
+		state.current = 0
+		state.start = start
+	case elapsed > window:
+		state.previous = 0
+		state.current = 0
+		state.start = start
+	}
+
+	weight := 1 - float64(now.Sub(start))/float64(window)
+	estimated := float64(state.previous)*weight + float64(state.current)
+
+	result := Result{Limit: limit.Requests, ResetAfter: start.Add(window).Sub(now)}
+	if estimated+1 <= float64(limit.Requests) {
+		state.current++
+		estimated++
+		result.Allowed = true
+	} else if state.previous > 0 && float64(state.current) < float64(limit.Requests) {
+		// Wait until enough of the previous window has slid out.
+		needed := (estimated + 1 - float64(limit.Requests)) / float64(state.previous)
+		result.RetryAfter = time.Duration(needed * float64(window))
+	} else {
+		result.RetryAfter = result.ResetAfter
+	}
+
+	result.Remaining = max(0, limit.Requests-int(math.Ceil(estimated)))
+	state.expireAt = start.Add(2 * window)
+	return result
+}
+
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < time.Minute {
+		return
+	}
+	s.lastCleanup = now
+
+	for key, state := range s.buckets {
+		if now.After(state.expireAt) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, state := range s.windows {
+		if now.After(state.expireAt) {
+			delete(s.windows, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
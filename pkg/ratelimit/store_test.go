@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestStore() (*MemoryStore, *clock) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = func() time.Time { return c.now }
+	return store, c
+}
+
+type step struct {
+	advance       time.Duration
+	wantAllowed   bool
+	wantRemaining int
+	wantRetry     time.Duration
+}
+
+func runSteps(t *testing.T, limit Limit, steps []step) {
+	t.Helper()
+	store, c := newTestStore()
+	for i, s := range steps {
+		c.advance(s.advance)
+		result, err := store.Allow(context.Background(), "key", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != s.wantAllowed || result.Remaining != s.wantRemaining || result.RetryAfter != s.wantRetry {
+			t.Errorf("step %d: %+v, want allowed %t remaining %d retry %s", i, result, s.wantAllowed, s.wantRemaining, s.wantRetry)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	// 2 requests per second with a burst of 3.
+	runSteps(t, Limit{Algorithm: TokenBucket, Requests: 2, Window: time.Second, Burst: 3}, []step{
+		{0, true, 2, 0},
+		{0, true, 1, 0},
+		{0, true, 0, 0},
+		{0, false, 0, 500 * time.Millisecond},
+		{250 * time.Millisecond, false, 0, 250 * time.Millisecond},
+		{250 * time.Millisecond, true, 0, 0},
+		// Refilling never goes over the burst.
+		{time.Hour, true, 2, 0},
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	// 4 requests per 10 seconds.
+	runSteps(t, Limit{Algorithm: SlidingWindow, Requests: 4, Window: 10 * time.Second}, []step{
+		{0, true, 3, 0},
+		{time.Second, true, 2, 0},
+		{time.Second, true, 1, 0},
+		{time.Second, true, 0, 0},
+		{time.Second, false, 0, 6 * time.Second},
+		// Next window, the 4 previous requests weigh 75%: 3 requests.
+		{8500 * time.Millisecond, true, 0, 0},
+		{0, false, 0, 2500 * time.Millisecond},
+		// Two windows later nothing counts anymore.
+		{20 * time.Second, true, 3, 0},
+	})
+}
+
+func TestMemoryStoreKeys(t *testing.T) {
+	store, c := newTestStore()
+	limit := Limit{Requests: 1, Window: time.Minute}
+	ctx := context.Background()
+
+	if result, _ := store.Allow(ctx, "a", limit); !result.Allowed {
+		t.Fatal("first request of a denied")
+	}
+	if result, _ := store.Allow(ctx, "b", limit); !result.Allowed {
+		t.Fatal("keys share their limit")
+	}
+	if result, _ := store.Allow(ctx, "a", limit); result.Allowed {
+		t.Fatal("second request of a allowed")
+	}
+
+	c.advance(2 * time.Minute)
+	store.Allow(ctx, "c", limit)
+	if _, ok := store.buckets["a"]; ok {
+		t.Error("expired bucket kept")
+	}
+}
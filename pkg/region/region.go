@@ -1,5 +1,10 @@
 package region
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Region string
 
 const (
@@ -46,3 +51,20 @@ func ToInt(regions []Region) int {
 	}
 	return mask
 }
+
+func (r Region) IsValid() bool {
+	if r == Global {
+		return true
+	}
+	_, ok := regionBitMap[r]
+	return ok
+}
+
+func (r *Region) UnmarshalText(text []byte) error {
+	value := Region(strings.ToLower(strings.TrimSpace(string(text))))
+	if value != "" && !value.IsValid() {
+		return fmt.Errorf("invalid region: %s", text)
+	}
+	*r = value
+	return nil
+}
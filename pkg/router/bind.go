@@ -0,0 +1,238 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/validation"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const defaultMaxFormMemory = 32 << 20
+
+var (
+	fileHeaderType  = reflect.TypeOf(&multipart.FileHeader{})
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader{})
+)
+
+// Bind fills a struct from the JSON or form body and from the fields tagged with path, query,
+// header and form, then validates it with its validate tags. Fields tagged with path, query or header
+// only take their value from it, never from the body.
+func Bind[T any](r *http.Request) (T, error) {
+	var request T
+
+	if err := bindBody(r, &request); err != nil {
+		return request, err
+	}
+
+	v := reflect.ValueOf(&request).Elem()
+	if v.Kind() == reflect.Struct {
+		clearSourceFields(v)
+
+		var errs []pkgErrors.FieldError
+		bindFields(r, v, &errs, "", nil)
+		if len(errs) > 0 {
+			return request, newBindError(errs)
+		}
+	}
+
+	if err := validation.Struct(request); err != nil {
+		return request, err
+	}
+	return request, nil
+}
+
+// BindQuery fills a struct from its query tags only and reports every bad parameter, either unparsable
+// or failing its validate tags, in a single validation error.
+func BindQuery[T any](r *http.Request) (T, error) {
+	var request T
+
+	v := reflect.ValueOf(&request).Elem()
+	if v.Kind() != reflect.Struct {
+		return request, fmt.Errorf("unsupported type: %T", request)
+	}
+
+	var errs []pkgErrors.FieldError
+	bindFields(r, v, &errs, "query", nil)
+	return request, validateWith(request, errs)
+}
+
+// validateWith validates request and reports errs plus the validation failures of the other fields
+// in a single validation error.
+func validateWith(request any, errs []pkgErrors.FieldError) error {
+	if err := validation.Struct(request); err != nil {
+		var appErr *pkgErrors.AppError
+		if !errors.As(err, &appErr) {
+			return err
+		}
+		for _, field := range appErr.Fields {
+			if !hasField(errs, field.Field) {
+				errs = append(errs, field)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return pkgErrors.NewFieldValidationError(errs)
+	}
+	return nil
+}
+
+func bindBody(r *http.Request, request any) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(defaultMaxFormMemory); err != nil {
+			return pkgErrors.NewBadRequestError("Invalid multipart form")
+		}
+		return nil
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return pkgErrors.NewBadRequestError("Invalid form")
+		}
+		return nil
+	}
+
+	return DecodeJSON(r, request, AllowEmptyBody())
+}
+
+// bindFields binds every tagged field, or only the fields tagged with source when it is not empty.
+// Files are checked against their file tag and the defaults in cfg, which may be nil.
+func bindFields(r *http.Request, v reflect.Value, errs *[]pkgErrors.FieldError, source string, cfg *formConfig) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldVal := v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindFields(r, fieldVal, errs, source, cfg)
+			continue
+		}
+
+		fieldSource, name, values := lookupValues(r, field)
+		if fieldSource == "" || (source != "" && fieldSource != source) {
+			continue
+		}
+
+		if fieldSource == "form" && (field.Type == fileHeaderType || field.Type == fileHeadersType) {
+			if r.MultipartForm == nil {
+				continue
+			}
+			if files := r.MultipartForm.File[name]; len(files) > 0 {
+				if !checkFiles(field, name, files, cfg, errs) {
+					continue
+				}
+				if field.Type == fileHeaderType {
+					fieldVal.Set(reflect.ValueOf(files[0]))
+				} else {
+					fieldVal.Set(reflect.ValueOf(files))
+				}
+			}
+			continue
+		}
+
+		// An empty value such as ?page= leaves the field unset, as MapFormRequest always did.
+		if len(values) == 0 || (values[0] == "" && fieldVal.Kind() != reflect.String) {
+			continue
+		}
+		if cfg != nil && cfg.lenientBools && fieldVal.Kind() == reflect.Bool {
+			value := strings.ToLower(values[0])
+			fieldVal.SetBool(value == "true" || value == "1")
+			continue
+		}
+		if err := setValues(fieldVal, values); err != nil {
+			*errs = append(*errs, pkgErrors.FieldError{
+				Field:   name,
+				Rule:    "type",
+				Message: fmt.Sprintf("%s %s: %s", fieldSource, name, err.Error()),
+			})
+		}
+	}
+}
+
+// clearSourceFields zeroes the fields bound from a path, query or header tag, which the JSON body may
+// have set through their Go name or json tag. Form fields are kept, a struct may accept both bodies.
+func clearSourceFields(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			clearSourceFields(v.Field(i))
+			continue
+		}
+		if hasSourceTag(field) {
+			v.Field(i).SetZero()
+		}
+	}
+}
+
+func hasSourceTag(field reflect.StructField) bool {
+	for _, tag := range []string{"path", "query", "header"} {
+		if field.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func newBindError(errs []pkgErrors.FieldError) error {
+	appErr := pkgErrors.NewBadRequestError(joinMessages(errs))
+	appErr.Fields = errs
+	return appErr
+}
+
+func joinMessages(fields []pkgErrors.FieldError) string {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, ", ")
+}
+
+func hasField(fields []pkgErrors.FieldError, name string) bool {
+	for _, field := range fields {
+		if field.Field == name {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupValues(r *http.Request, field reflect.StructField) (string, string, []string) {
+	if name := field.Tag.Get("path"); name != "" {
+		if value := chi.URLParam(r, name); value != "" {
+			return "path", name, []string{value}
+		}
+		return "path", name, nil
+	}
+	if name := field.Tag.Get("query"); name != "" {
+		return "query", name, r.URL.Query()[name]
+	}
+	if name := field.Tag.Get("header"); name != "" {
+		return "header", name, r.Header.Values(name)
+	}
+	if name := field.Tag.Get("form"); name != "" {
+		if r.Form == nil {
+			return "form", name, nil
+		}
+		return "form", name, r.Form[name]
+	}
+	return "", "", nil
+}
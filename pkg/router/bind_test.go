@@ -0,0 +1,203 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type BindPagination struct {
+	Page int `query:"page" validate:"min=1"`
+}
+
+type bindRequest struct {
+	BindPagination
+	ID     int      `path:"id"`
+	Tenant string   `header:"X-Tenant"`
+	Tags   []string `query:"tag"`
+	Title  string   `json:"title" validate:"required"`
+}
+
+func newBindRequest(method, target, contentType, body string, params map[string]string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	routeCtx := chi.NewRouteContext()
+	for key, value := range params {
+		routeCtx.URLParams.Add(key, value)
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		name   string
+		r      *http.Request
+		header map[string]string
+		want   bindRequest
+		status int
+	}{
+		{
+			name:   "binds every source",
+			r:      newBindRequest(http.MethodPost, "/songs/7?page=2&tag=a&tag=b", "application/json", `{"title":"Song"}`, map[string]string{"id": "7"}),
+			header: map[string]string{"X-Tenant": "melodia"},
+			want:   bindRequest{BindPagination: BindPagination{Page: 2}, ID: 7, Tenant: "melodia", Tags: []string{"a", "b"}, Title: "Song"},
+		},
+		{
+			name: "ignores source fields in the body",
+			r: newBindRequest(http.MethodPost, "/songs/7?page=1", "application/json",
+				`{"title":"Song","ID":99,"Tenant":"evil","Tags":["x"],"Page":5}`, map[string]string{"id": "7"}),
+			want: bindRequest{BindPagination: BindPagination{Page: 1}, ID: 7, Title: "Song"},
+		},
+		{
+			name:   "reports unparsable parameters",
+			r:      newBindRequest(http.MethodPost, "/songs/x?page=1", "application/json", `{"title":"Song"}`, map[string]string{"id": "x"}),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "validates the result",
+			r:      newBindRequest(http.MethodPost, "/songs/7?page=0", "application/json", `{"title":"Song"}`, map[string]string{"id": "7"}),
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "rejects invalid JSON",
+			r:      newBindRequest(http.MethodPost, "/songs/7?page=1", "application/json", `{"title":`, map[string]string{"id": "7"}),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for key, value := range test.header {
+				test.r.Header.Set(key, value)
+			}
+			got, err := Bind[bindRequest](test.r)
+			if test.status != 0 {
+				var appErr *pkgErrors.AppError
+				if !errors.As(err, &appErr) || appErr.HTTPCode != test.status {
+					t.Fatalf("err = %v, want status %d", err, test.status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+type bindFormRequest struct {
+	Title  string                `form:"title" validate:"required"`
+	Public bool                  `form:"public"`
+	Cover  *multipart.FileHeader `form:"cover" file:"maxsize=1KB,types=image/png" validate:"required"`
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+func newFormRequest(t *testing.T, fields map[string]string, fileName string, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fileName != "" {
+		part, err := writer.CreateFormFile("cover", fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(content)
+	}
+	writer.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/songs", &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+func TestBindForm(t *testing.T) {
+	tests := []struct {
+		name   string
+		r      func(t *testing.T) *http.Request
+		status int
+		fields []string
+	}{
+		{
+			name: "binds fields and files",
+			r: func(t *testing.T) *http.Request {
+				return newFormRequest(t, map[string]string{"title": "Song", "public": "true"}, "cover.png", pngHeader)
+			},
+		},
+		{
+			name: "rejects files of other types",
+			r: func(t *testing.T) *http.Request {
+				return newFormRequest(t, map[string]string{"title": "Song"}, "cover.png", []byte("not an image"))
+			},
+			status: http.StatusUnprocessableEntity,
+			fields: []string{"cover"},
+		},
+		{
+			name: "rejects big files",
+			r: func(t *testing.T) *http.Request {
+				return newFormRequest(t, map[string]string{"title": "Song"}, "cover.png", append(pngHeader, make([]byte, 2048)...))
+			},
+			status: http.StatusUnprocessableEntity,
+			fields: []string{"cover"},
+		},
+		{
+			name: "reports every failure",
+			r: func(t *testing.T) *http.Request {
+				return newFormRequest(t, map[string]string{"public": "maybe"}, "", nil)
+			},
+			status: http.StatusUnprocessableEntity,
+			fields: []string{"public", "title", "cover"},
+		},
+		{
+			name: "rejects JSON bodies",
+			r: func(t *testing.T) *http.Request {
+				return newBindRequest(http.MethodPost, "/songs", "application/json", `{}`, nil)
+			},
+			status: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := BindForm[bindFormRequest](test.r(t))
+			if test.status == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Title != "Song" || !got.Public || got.Cover == nil || got.Cover.Header.Get("Content-Type") != "image/png" {
+					t.Fatalf("got %+v", got)
+				}
+				return
+			}
+
+			var appErr *pkgErrors.AppError
+			if !errors.As(err, &appErr) || appErr.HTTPCode != test.status {
+				t.Fatalf("err = %v, want status %d", err, test.status)
+			}
+			for _, field := range test.fields {
+				if !hasField(appErr.Fields, field) {
+					t.Errorf("missing error for %s in %+v", field, appErr.Fields)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+type decodeConfig struct {
+	disallowUnknownFields bool
+	rejectTrailingData    bool
+	allowEmpty            bool
+	maxBytes              int64
+	contentTypes          []string
+	requireContentType    bool
+}
+
+type DecodeOption func(cfg *decodeConfig)
+
+// DisallowUnknownFields rejects bodies with fields that are not in the target struct.
+func DisallowUnknownFields() DecodeOption {
+	return func(cfg *decodeConfig) {
+		cfg.disallowUnknownFields = true
+	}
+}
+
+// RejectTrailingData rejects bodies with anything but whitespace after the first JSON value.
+func RejectTrailingData() DecodeOption {
+	return func(cfg *decodeConfig) {
+		cfg.rejectTrailingData = true
+	}
+}
+
+// AllowEmptyBody leaves the target untouched when the body is empty instead of returning 400.
+func AllowEmptyBody() DecodeOption {
+	return func(cfg *decodeConfig) {
+		cfg.allowEmpty = true
+	}
+}
+
+// MaxBodySize limits the body to n bytes. Bigger bodies return 413.
+func MaxBodySize(n int64) DecodeOption {
+	return func(cfg *decodeConfig) {
+		cfg.maxBytes = n
+	}
+}
+
+// RequireContentType returns 415 unless the request media type is one of types.
+// Without types, application/json and any +json media type are accepted.
+func RequireContentType(types ...string) DecodeOption {
+	return func(cfg *decodeConfig) {
+		cfg.requireContentType = true
+		cfg.contentTypes = types
+	}
+}
+
+// Strict combines DisallowUnknownFields, RejectTrailingData, RequireContentType and MaxBodySize.
+func Strict(maxBytes int64) DecodeOption {
+	return func(cfg *decodeConfig) {
+		DisallowUnknownFields()(cfg)
+		RejectTrailingData()(cfg)
+		RequireContentType()(cfg)
+		MaxBodySize(maxBytes)(cfg)
+	}
+}
+
+// DecodeJSON decodes the request body into v and reports failures as AppErrors with a precise message.
+func DecodeJSON(r *http.Request, v any, opts ...DecodeOption) error {
+	cfg := &decodeConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.requireContentType {
+		if err := checkContentType(r, cfg.contentTypes); err != nil {
+			return err
+		}
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		if cfg.allowEmpty {
+			return nil
+		}
+		return pkgErrors.NewBadRequestError("Request body must not be empty")
+	}
+
+	body := r.Body
+	if cfg.maxBytes > 0 {
+		body = http.MaxBytesReader(nil, r.Body, cfg.maxBytes)
+	}
+
+	decoder := json.NewDecoder(body)
+	if cfg.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) && cfg.allowEmpty {
+			return nil
+		}
+		return decodeError(err, decoder)
+	}
+
+	if cfg.rejectTrailingData {
+		offset := decoder.InputOffset()
+		var extra json.RawMessage
+		if err := decoder.Decode(&extra); !errors.Is(err, io.EOF) {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return decodeError(err, decoder)
+			}
+			return pkgErrors.NewBadRequestError(fmt.Sprintf("Request body must contain a single JSON value, found trailing data after offset %d", offset))
+		}
+	}
+	return nil
+}
+
+func checkContentType(r *http.Request, allowed []string) error {
+	header := r.Header.Get("Content-Type")
+	if header == "" {
+		return pkgErrors.NewUnsupportedMediaTypeError("Content-Type header is required")
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return pkgErrors.NewUnsupportedMediaTypeError(fmt.Sprintf("Invalid Content-Type %q", header))
+	}
+
+	if len(allowed) == 0 {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return nil
+		}
+		return pkgErrors.NewUnsupportedMediaTypeError(fmt.Sprintf("Content-Type %s is not supported, expected application/json", mediaType))
+	}
+
+	for _, contentType := range allowed {
+		if strings.EqualFold(mediaType, contentType) {
+			return nil
+		}
+	}
+	return pkgErrors.NewUnsupportedMediaTypeError(fmt.Sprintf("Content-Type %s is not supported, expected %s", mediaType, strings.Join(allowed, " or ")))
+}
+
+func decodeError(err error, decoder *json.Decoder) error {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+		invalidErr  *json.InvalidUnmarshalError
+	)
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return pkgErrors.NewPayloadTooLargeError(fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit))
+	case errors.As(err, &syntaxErr):
+		return pkgErrors.NewBadRequestError(fmt.Sprintf("Malformed JSON at offset %d", syntaxErr.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return pkgErrors.NewBadRequestError("Malformed JSON, unexpected end of body")
+	case errors.Is(err, io.EOF):
+		return pkgErrors.NewBadRequestError("Request body must not be empty")
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return pkgErrors.NewBadRequestError(fmt.Sprintf("Request body must be %s, found %s at offset %d", jsonKind(typeErr.Type), typeErr.Value, typeErr.Offset))
+		}
+		appErr := pkgErrors.NewBadRequestError(fmt.Sprintf("field `%s` must be %s at offset %d", typeErr.Field, jsonKind(typeErr.Type), typeErr.Offset))
+		appErr.Fields = []pkgErrors.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: appErr.Message,
+		}}
+		return appErr
+	case errors.As(err, &invalidErr):
+		return err
+	}
+
+	// encoding/json has no error type for unknown fields.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name := strings.Trim(field, `"`)
+		appErr := pkgErrors.NewBadRequestError(fmt.Sprintf("unknown field `%s` at offset %d", name, decoder.InputOffset()))
+		appErr.Fields = []pkgErrors.FieldError{{
+			Field:   name,
+			Rule:    "unknown",
+			Message: appErr.Message,
+		}}
+		return appErr
+	}
+
+	return pkgErrors.NewBadRequestError(fmt.Sprintf("Invalid request body: %s", err.Error()))
+}
+
+func jsonKind(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Implements(textUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return "a string"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	}
+	return "a " + t.String()
+}
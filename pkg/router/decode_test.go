@@ -0,0 +1,199 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+type decodeSong struct {
+	Title    string    `json:"title"`
+	Plays    int       `json:"plays"`
+	Tags     []string  `json:"tags"`
+	Released time.Time `json:"released"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		opts        []DecodeOption
+		want        decodeSong
+		status      int
+		message     string
+		field       string
+	}{
+		{
+			name: "decodes",
+			body: `{"title":"Song","plays":3,"extra":true}`,
+			want: decodeSong{Title: "Song", Plays: 3},
+		},
+		{
+			name:    "empty body",
+			status:  http.StatusBadRequest,
+			message: "Request body must not be empty",
+		},
+		{
+			name: "empty body allowed",
+			opts: []DecodeOption{AllowEmptyBody()},
+		},
+		{
+			name:    "syntax error",
+			body:    `{"title":}`,
+			status:  http.StatusBadRequest,
+			message: "Malformed JSON at offset 10",
+		},
+		{
+			name:    "truncated",
+			body:    `{"title":"Song"`,
+			status:  http.StatusBadRequest,
+			message: "Malformed JSON, unexpected end of body",
+		},
+		{
+			name:    "wrong field type",
+			body:    `{"plays":"many"}`,
+			status:  http.StatusBadRequest,
+			message: "field `plays` must be a number at offset 15",
+			field:   "plays",
+		},
+		{
+			name:    "text unmarshaler field",
+			body:    `{"released":5}`,
+			status:  http.StatusBadRequest,
+			message: "field `released` must be a string at offset 13",
+			field:   "released",
+		},
+		{
+			name:    "wrong body type",
+			body:    `[1, 2]`,
+			status:  http.StatusBadRequest,
+			message: "Request body must be an object, found array at offset 1",
+		},
+		{
+			name:    "unknown field",
+			body:    `{"title":"Song","extra":true}`,
+			opts:    []DecodeOption{DisallowUnknownFields()},
+			status:  http.StatusBadRequest,
+			message: "unknown field `extra` at offset 29",
+			field:   "extra",
+		},
+		{
+			name: "trailing data allowed",
+			body: `{"title":"Song"} {"title":"Other"}`,
+			want: decodeSong{Title: "Song"},
+		},
+		{
+			name:    "trailing data",
+			body:    `{"title":"Song"} {"title":"Other"}`,
+			opts:    []DecodeOption{RejectTrailingData()},
+			status:  http.StatusBadRequest,
+			message: "Request body must contain a single JSON value, found trailing data after offset 16",
+		},
+		{
+			name: "trailing whitespace",
+			body: "{\"title\":\"Song\"}\n\t ",
+			opts: []DecodeOption{RejectTrailingData()},
+			want: decodeSong{Title: "Song"},
+		},
+		{
+			name:    "too large",
+			body:    `{"title":"` + strings.Repeat("a", 64) + `"}`,
+			opts:    []DecodeOption{MaxBodySize(32)},
+			status:  http.StatusRequestEntityTooLarge,
+			message: "Request body must not exceed 32 bytes",
+		},
+		{
+			name:    "too large after the value",
+			body:    `{"title":"Song"}` + strings.Repeat(" ", 16) + `{}`,
+			opts:    []DecodeOption{MaxBodySize(32), RejectTrailingData()},
+			status:  http.StatusRequestEntityTooLarge,
+			message: "Request body must not exceed 32 bytes",
+		},
+		{
+			name:    "content type required",
+			body:    `{}`,
+			opts:    []DecodeOption{RequireContentType()},
+			status:  http.StatusUnsupportedMediaType,
+			message: "Content-Type header is required",
+		},
+		{
+			name:        "JSON content type",
+			contentType: "application/merge-patch+json; charset=utf-8",
+			body:        `{"title":"Song"}`,
+			opts:        []DecodeOption{RequireContentType()},
+			want:        decodeSong{Title: "Song"},
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "text/plain",
+			body:        `{}`,
+			opts:        []DecodeOption{RequireContentType()},
+			status:      http.StatusUnsupportedMediaType,
+			message:     "Content-Type text/plain is not supported, expected application/json",
+		},
+		{
+			name:        "allowed content types",
+			contentType: "application/json",
+			body:        `{}`,
+			opts:        []DecodeOption{RequireContentType("application/vnd.melodia+json", "text/json")},
+			status:      http.StatusUnsupportedMediaType,
+			message:     "Content-Type application/json is not supported, expected application/vnd.melodia+json or text/json",
+		},
+		{
+			name:        "invalid content type",
+			contentType: "application/",
+			body:        `{}`,
+			opts:        []DecodeOption{RequireContentType()},
+			status:      http.StatusUnsupportedMediaType,
+			message:     `Invalid Content-Type "application/"`,
+		},
+		{
+			name:        "strict",
+			contentType: "application/json",
+			body:        `{"title":"Song","plays":1}`,
+			opts:        []DecodeOption{Strict(1024)},
+			want:        decodeSong{Title: "Song", Plays: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/songs", strings.NewReader(tt.body))
+			if tt.body == "" {
+				r.Body = http.NoBody
+			}
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var got decodeSong
+			err := DecodeJSON(r, &got, tt.opts...)
+			if tt.status == 0 {
+				if err != nil {
+					t.Fatalf("DecodeJSON: %v", err)
+				}
+				if got.Title != tt.want.Title || got.Plays != tt.want.Plays {
+					t.Errorf("decoded %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+
+			var appErr *pkgErrors.AppError
+			if !errors.As(err, &appErr) {
+				t.Fatalf("error = %v, want an AppError", err)
+			}
+			if appErr.HTTPCode != tt.status || appErr.Message != tt.message {
+				t.Errorf("error = %d %q, want %d %q", appErr.HTTPCode, appErr.Message, tt.status, tt.message)
+			}
+			if tt.field != "" && (len(appErr.Fields) != 1 || appErr.Fields[0].Field != tt.field) {
+				t.Errorf("fields = %+v, want %s", appErr.Fields, tt.field)
+			}
+		})
+	}
+}
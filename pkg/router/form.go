@@ -0,0 +1,239 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+type formConfig struct {
+	maxMemory    int64
+	maxFileSize  int64
+	allowedTypes []string
+	// lenientBools sets bools to true for "true" or "1" and to false for any other value, as
+	// MapFormRequest always did.
+	lenientBools bool
+}
+
+type FormOption func(cfg *formConfig)
+
+// WithMaxMemory sets how much of the form is kept in memory, the rest goes to temporary files. Defaults to 32MB.
+func WithMaxMemory(n int64) FormOption {
+	return func(cfg *formConfig) {
+		cfg.maxMemory = n
+	}
+}
+
+// WithMaxFileSize limits every file without a maxsize in its file tag.
+func WithMaxFileSize(n int64) FormOption {
+	return func(cfg *formConfig) {
+		cfg.maxFileSize = n
+	}
+}
+
+// WithAllowedTypes limits every file without types in its file tag, e.g. WithAllowedTypes("image/*").
+func WithAllowedTypes(types ...string) FormOption {
+	return func(cfg *formConfig) {
+		cfg.allowedTypes = types
+	}
+}
+
+// BindForm fills a struct from a multipart or urlencoded form using its form tags, then validates it.
+// Besides the types supported by Bind, file fields can be *multipart.FileHeader or []*multipart.FileHeader
+// and be limited with a file tag:
+//
+//	Cover  *multipart.FileHeader   `form:"cover" file:"maxsize=5MB,types=image/png image/jpeg" validate:"required"`
+//	Tracks []*multipart.FileHeader `form:"tracks" file:"maxsize=200MB,types=audio/*" validate:"min=1,max=20"`
+//
+// The type of each file is sniffed from its content and replaces the Content-Type sent by the client.
+// Every unparsable value, rejected file and validation failure is reported in a single validation error.
+func BindForm[T any](r *http.Request, opts ...FormOption) (T, error) {
+	var request T
+
+	cfg := &formConfig{maxMemory: defaultMaxFormMemory}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	v := reflect.ValueOf(&request).Elem()
+	if v.Kind() != reflect.Struct {
+		return request, fmt.Errorf("unsupported type: %T", request)
+	}
+
+	if err := parseForm(r, cfg.maxMemory); err != nil {
+		return request, err
+	}
+
+	var errs []pkgErrors.FieldError
+	bindFields(r, v, &errs, "form", cfg)
+	return request, validateWith(request, errs)
+}
+
+func parseForm(r *http.Request, maxMemory int64) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		if r.MultipartForm != nil {
+			return nil
+		}
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return pkgErrors.NewPayloadTooLargeError(fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit))
+			}
+			return pkgErrors.NewBadRequestError("Invalid multipart form")
+		}
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return pkgErrors.NewBadRequestError("Invalid form")
+		}
+	default:
+		return pkgErrors.NewUnsupportedMediaTypeError("Content-Type must be multipart/form-data or application/x-www-form-urlencoded")
+	}
+	return nil
+}
+
+type fileRules struct {
+	maxSize int64
+	types   []string
+}
+
+// checkFiles reports every file of the field that breaks its rules and returns whether all of them passed.
+func checkFiles(field reflect.StructField, name string, files []*multipart.FileHeader, cfg *formConfig, errs *[]pkgErrors.FieldError) bool {
+	rules, err := parseFileTag(field.Tag.Get("file"))
+	if err != nil {
+		*errs = append(*errs, pkgErrors.FieldError{Field: name, Rule: "file", Message: err.Error()})
+		return false
+	}
+	if cfg != nil {
+		if rules.maxSize == 0 {
+			rules.maxSize = cfg.maxFileSize
+		}
+		if rules.types == nil {
+			rules.types = cfg.allowedTypes
+		}
+	}
+
+	valid := true
+	for _, file := range files {
+		if rules.maxSize > 0 && file.Size > rules.maxSize {
+			*errs = append(*errs, pkgErrors.FieldError{
+				Field:   name,
+				Rule:    "maxsize",
+				Param:   strconv.FormatInt(rules.maxSize, 10),
+				Message: fmt.Sprintf("%s: %s must be at most %d bytes", name, file.Filename, rules.maxSize),
+			})
+			valid = false
+			continue
+		}
+
+		detected, err := sniffFile(file)
+		if err != nil {
+			*errs = append(*errs, pkgErrors.FieldError{Field: name, Rule: "file", Message: fmt.Sprintf("%s: %s could not be read", name, file.Filename)})
+			valid = false
+			continue
+		}
+		if len(rules.types) > 0 && !matchesType(detected, rules.types) {
+			*errs = append(*errs, pkgErrors.FieldError{
+				Field:   name,
+				Rule:    "types",
+				Param:   strings.Join(rules.types, " "),
+				Message: fmt.Sprintf("%s: %s has type %s, expected one of [%s]", name, file.Filename, detected.String(), strings.Join(rules.types, ", ")),
+			})
+			valid = false
+			continue
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(detected.String())
+		file.Header.Set("Content-Type", mediaType)
+	}
+	return valid
+}
+
+func sniffFile(file *multipart.FileHeader) (*mimetype.MIME, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return mimetype.DetectReader(f)
+}
+
+// matchesType accepts exact types, their aliases and wildcards such as "audio/*".
+func matchesType(detected *mimetype.MIME, allowed []string) bool {
+	mediaType, _, _ := mime.ParseMediaType(detected.String())
+	for _, allowedType := range allowed {
+		if prefix, ok := strings.CutSuffix(allowedType, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if detected.Is(allowedType) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFileTag parses a tag such as "maxsize=5MB,types=image/png image/jpeg".
+func parseFileTag(tag string) (fileRules, error) {
+	var rules fileRules
+	if tag == "" {
+		return rules, nil
+	}
+
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "maxsize":
+			size, err := parseSize(value)
+			if err != nil {
+				return rules, err
+			}
+			rules.maxSize = size
+		case "types":
+			rules.types = strings.Fields(value)
+		default:
+			return rules, fmt.Errorf("unknown file rule: %s", key)
+		}
+	}
+	return rules, nil
+}
+
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize parses sizes such as "512", "100KB" or "5MB".
+func parseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(number), unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,50 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Handle registers a typed handler. The pattern has the form "METHOD /path/{param}". The request is
+// bound with Bind and the response is encoded as JSON, errors go through the ErrorHandler.
+func Handle[Req any, Resp any](rt *Router, pattern string, fn func(ctx context.Context, req Req) (Resp, error), opts ...RouteOption) {
+	method, path, ok := strings.Cut(strings.TrimSpace(pattern), " ")
+	if !ok {
+		panic(fmt.Sprintf("router: invalid pattern %q, expected \"METHOD /path\"", pattern))
+	}
+
+	cfg := newRouteConfig(opts)
+	if cfg.request == nil {
+		cfg.request = reflect.TypeFor[Req]()
+	}
+	if cfg.responses == nil {
+		cfg.responses = map[int]reflect.Type{cfg.status: reflect.TypeFor[Resp]()}
+		if cfg.status == http.StatusNoContent {
+			cfg.responses[cfg.status] = nil
+		}
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		req, err := Bind[Req](r)
+		if err != nil {
+			return err
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			return err
+		}
+
+		if cfg.status == http.StatusNoContent {
+			NoContent(w)
+			return nil
+		}
+		JSON(w, cfg.status, resp)
+		return nil
+	}
+
+	rt.register(strings.ToUpper(method), strings.TrimSpace(path), handler, cfg)
+}
@@ -1,10 +1,10 @@
 package router
 
 import (
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"mime/multipart"
 	"net/http"
 	"reflect"
 	"strconv"
@@ -39,6 +39,13 @@ func NoContent(w http.ResponseWriter) {
 	JSON(w, http.StatusNoContent, nil)
 }
 
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	uuidType            = reflect.TypeOf(uuid.UUID{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
 func GetUrlParam[T any](r *http.Request, param string) (T, error) {
 	var value T
 	paramValue := chi.URLParam(r, param)
@@ -68,12 +75,16 @@ func GetUrlParamOrCtx[T any](r *http.Request, param string, defaultParam string,
 	return result, nil
 }
 
+// GetQueryParam returns nil when the param is missing. Slice types read repeated and comma separated values.
 func GetQueryParam[T any](r *http.Request, param string) (*T, error) {
-	paramValue := r.URL.Query().Get(param)
-	if paramValue == "" {
+	values := r.URL.Query()[param]
+	if len(values) == 0 || values[0] == "" {
 		return nil, nil
 	}
-	result, err := parseParam[T](paramValue)
+	if !isSliceParam(reflect.TypeFor[T]()) {
+		values = values[:1]
+	}
+	result, err := parseParams[T](values)
 	if err != nil {
 		return &result, pkgErrors.NewBadRequestError(err.Error())
 	}
@@ -81,41 +92,16 @@ func GetQueryParam[T any](r *http.Request, param string) (*T, error) {
 }
 
 func parseParam[T any](paramValue string) (T, error) {
-	var value T
-	var anyValue any
+	return parseParams[T]([]string{paramValue})
+}
 
-	switch any(value).(type) {
-	case string:
-		anyValue = paramValue
-	case int:
-		v, err := strconv.Atoi(paramValue)
-		if err != nil {
-			return value, fmt.Errorf("invalid value: %s. Expected int", paramValue)
-		}
-		anyValue = v
-	case uuid.UUID:
-		v, err := uuid.Parse(paramValue)
-		if err != nil {
-			return value, fmt.Errorf("invalid value: %s. Expected uuid", paramValue)
-		}
-		anyValue = v
-	case bool:
-		v, err := strconv.ParseBool(paramValue)
-		if err != nil {
-			return value, fmt.Errorf("invalid value: %s. Expected bool", paramValue)
-		}
-		anyValue = v
-	case time.Time:
-		v, err := time.Parse(time.RFC3339, paramValue)
-		if err != nil {
-			return value, fmt.Errorf("invalid value: %s. Expected time", paramValue)
-		}
-		anyValue = v
-	default:
-		return value, fmt.Errorf("unsupported type: %T", value)
+func parseParams[T any](values []string) (T, error) {
+	var value T
+	v := reflect.ValueOf(&value).Elem()
+	if err := setValues(v, values); err != nil {
+		return value, err
 	}
-
-	return anyValue.(T), nil
+	return value, nil
 }
 
 func MapRequest[T any](r *http.Request) (T, error) {
@@ -126,52 +112,151 @@ func MapRequest[T any](r *http.Request) (T, error) {
 	return request, nil
 }
 
+// MapFormRequest fills the form tagged fields of T without validating them. Bools are true for "true"
+// or "1" and false for anything else, such as the "on" of HTML checkboxes.
+//
+// Deprecated: use BindForm, which also limits files and validates.
 func MapFormRequest[T any](r *http.Request) (T, error) {
 	var request T
 	v := reflect.ValueOf(&request).Elem()
-	t := v.Type()
+	if v.Kind() != reflect.Struct {
+		return request, fmt.Errorf("unsupported type: %T", request)
+	}
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		formTag := field.Tag.Get("form")
+	if r.Form == nil {
+		_ = r.ParseMultipartForm(defaultMaxFormMemory)
+	}
 
-		if formTag == "" {
-			continue
-		}
+	var errs []pkgErrors.FieldError
+	bindFields(r, v, &errs, "form", &formConfig{lenientBools: true})
+	if len(errs) > 0 {
+		return request, newBindError(errs)
+	}
+	return request, nil
+}
 
-		if field.Type == reflect.TypeOf(&multipart.FileHeader{}) {
-			if fileHeaders, ok := r.MultipartForm.File[formTag]; ok && len(fileHeaders) > 0 {
-				v.Field(i).Set(reflect.ValueOf(fileHeaders[0]))
+// setValues fills slices from repeated or comma separated values, e.g. ?genre=rock&genre=pop or ?genre=rock,pop,
+// or from a single JSON array.
+func setValues(field reflect.Value, values []string) error {
+	if isSliceParam(field.Type()) {
+		if len(values) == 1 && strings.HasPrefix(strings.TrimSpace(values[0]), "[") {
+			slice := reflect.New(field.Type())
+			if err := json.Unmarshal([]byte(values[0]), slice.Interface()); err != nil {
+				return fmt.Errorf("invalid value: %s. Expected json array", values[0])
 			}
-			continue
+			field.Set(slice.Elem())
+			return nil
 		}
-
-		formValue := r.FormValue(formTag)
-		if formValue == "" {
-			continue
+		slice := reflect.MakeSlice(field.Type(), 0, len(values))
+		for _, value := range values {
+			for _, part := range strings.Split(value, ",") {
+				if part = strings.TrimSpace(part); part == "" {
+					continue
+				}
+				elem, err := parseValue(field.Type().Elem(), part)
+				if err != nil {
+					return err
+				}
+				slice = reflect.Append(slice, elem)
+			}
 		}
+		field.Set(slice)
+		return nil
+	}
+
+	value, err := parseValue(field.Type(), values[0])
+	if err != nil {
+		return err
+	}
+	field.Set(value)
+	return nil
+}
 
-		fieldVal := v.Field(i)
+func parseValue(t reflect.Type, raw string) (reflect.Value, error) {
+	if t.Kind() == reflect.Ptr {
+		elem, err := parseValue(t.Elem(), raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
 
-		switch field.Type.Kind() {
-		case reflect.String:
-			fieldVal.SetString(formValue)
+	value := reflect.New(t).Elem()
 
-		case reflect.Bool:
-			lowerVal := strings.ToLower(formValue)
-			isTrue := lowerVal == "true" || lowerVal == "1"
-			fieldVal.SetBool(isTrue)
+	switch t {
+	case uuidType:
+		v, err := uuid.Parse(raw)
+		if err != nil {
+			return value, fmt.Errorf("invalid value: %s. Expected uuid", raw)
+		}
+		value.Set(reflect.ValueOf(v))
+		return value, nil
+	case timeType:
+		v, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			v, err = time.Parse(time.DateOnly, raw)
+		}
+		if err != nil {
+			return value, fmt.Errorf("invalid value: %s. Expected time", raw)
+		}
+		value.Set(reflect.ValueOf(v))
+		return value, nil
+	case durationType:
+		v, err := time.ParseDuration(raw)
+		if err != nil {
+			return value, fmt.Errorf("invalid value: %s. Expected duration", raw)
+		}
+		value.SetInt(int64(v))
+		return value, nil
+	}
 
-		case reflect.Slice, reflect.Struct:
-			newVal := reflect.New(field.Type)
-			if err := json.Unmarshal([]byte(formValue), newVal.Interface()); err != nil {
-				return request, fmt.Errorf("failed to unmarshal JSON for field %s: %w", field.Name, err)
-			}
-			fieldVal.Set(newVal.Elem())
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		if err := value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return value, fmt.Errorf("invalid value: %s. %s", raw, err.Error())
+		}
+		return value, nil
+	}
 
-		default:
+	switch t.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return value, fmt.Errorf("invalid value: %s. Expected bool", raw)
+		}
+		value.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return value, fmt.Errorf("invalid value: %s. Expected %s", raw, t.Kind())
+		}
+		value.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return value, fmt.Errorf("invalid value: %s. Expected %s", raw, t.Kind())
 		}
+		value.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return value, fmt.Errorf("invalid value: %s. Expected %s", raw, t.Kind())
+		}
+		value.SetFloat(v)
+	case reflect.Struct, reflect.Slice, reflect.Map:
+		if err := json.Unmarshal([]byte(raw), value.Addr().Interface()); err != nil {
+			return value, fmt.Errorf("invalid value: %s. Expected json", raw)
+		}
+	default:
+		return value, fmt.Errorf("unsupported type: %s", t)
 	}
 
-	return request, nil
+	return value, nil
+}
+
+func isSliceParam(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t != fileHeadersType && t.Elem().Kind() != reflect.Uint8
 }
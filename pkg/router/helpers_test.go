@@ -0,0 +1,98 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMapFormRequestBools(t *testing.T) {
+	type request struct {
+		Public bool `form:"public"`
+	}
+
+	tests := map[string]bool{
+		"true":  true,
+		"TRUE":  true,
+		"1":     true,
+		"on":    false,
+		"false": false,
+		"0":     false,
+		"yes":   false,
+	}
+
+	for value, want := range tests {
+		form := url.Values{"public": {value}}
+		r := httptest.NewRequest(http.MethodPost, "/songs", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		got, err := MapFormRequest[request](r)
+		if err != nil {
+			t.Fatalf("%q: %v", value, err)
+		}
+		if got.Public != want {
+			t.Errorf("%q: public = %v, want %v", value, got.Public, want)
+		}
+	}
+}
+
+func TestEmptyValues(t *testing.T) {
+	type formRequest struct {
+		Age    int    `form:"age"`
+		Public bool   `form:"public"`
+		Name   string `form:"name"`
+	}
+	type queryRequest struct {
+		Age    int    `query:"age"`
+		Public bool   `query:"public"`
+		Name   string `query:"name"`
+	}
+
+	values := url.Values{"age": {""}, "public": {""}, "name": {""}}
+	newFormRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/songs", strings.NewReader(values.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	tests := []struct {
+		name string
+		bind func() (any, error)
+		want any
+	}{
+		{"MapFormRequest", func() (any, error) { return MapFormRequest[formRequest](newFormRequest()) }, formRequest{}},
+		{"BindForm", func() (any, error) { return BindForm[formRequest](newFormRequest()) }, formRequest{}},
+		{"BindQuery", func() (any, error) {
+			return BindQuery[queryRequest](httptest.NewRequest(http.MethodGet, "/songs?"+values.Encode(), nil))
+		}, queryRequest{}},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.bind()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s = %+v, want the zero value", tt.name, got)
+		}
+	}
+}
+
+func TestGetQueryParam(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/songs?page=2&bad=x", nil)
+
+	page, err := GetQueryParam[int](r, "page")
+	if err != nil || page == nil || *page != 2 {
+		t.Fatalf("page = %v, %v", page, err)
+	}
+	missing, err := GetQueryParam[int](r, "size")
+	if err != nil || missing != nil {
+		t.Fatalf("size = %v, %v", missing, err)
+	}
+	if _, err := GetQueryParam[int](r, "bad"); err == nil {
+		t.Fatal("expected an error for bad")
+	}
+}
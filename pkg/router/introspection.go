@@ -0,0 +1,101 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Routes returns every route registered in the router and its sub routers, in registration order.
+func (rt *Router) Routes() []RouteInfo {
+	return rt.routes.list()
+}
+
+// Walk calls fn for each route and stops at the first error.
+func (rt *Router) Walk(fn func(route RouteInfo) error) error {
+	for _, route := range rt.routes.list() {
+		if err := fn(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnauthenticatedWriteRoutes returns the POST, PUT, PATCH and DELETE routes not known to require
+// authentication, see RouteInfo.Auth.
+func (rt *Router) UnauthenticatedWriteRoutes() []RouteInfo {
+	var result []RouteInfo
+	for _, route := range rt.routes.list() {
+		if route.Auth == nil && isWriteMethod(route.Method) {
+			result = append(result, route)
+		}
+	}
+	return result
+}
+
+// PrintRoutes writes the route table sorted by pattern. Unauthenticated write routes are flagged with "!".
+func (rt *Router) PrintRoutes(w io.Writer) error {
+	routes := rt.routes.list()
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern == routes[j].Pattern {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Pattern < routes[j].Pattern
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tMETHOD\tPATTERN\tAUTH\tMIDDLEWARES")
+
+	flagged := 0
+	for _, route := range routes {
+		flag := ""
+		if route.Auth == nil && isWriteMethod(route.Method) {
+			flag = "!"
+			flagged++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", flag, route.Method, route.Pattern, describeAuth(route), strings.Join(route.Middlewares, " > "))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if flagged > 0 {
+		_, err := fmt.Fprintf(w, "\n%d write route(s) without authentication\n", flagged)
+		return err
+	}
+	return nil
+}
+
+func describeAuth(route RouteInfo) string {
+	if route.Auth == nil {
+		return "-"
+	}
+	var parts []string
+	if len(route.Auth.Roles) > 0 {
+		parts = append(parts, "roles="+strings.Join(toStrings(route.Auth.Roles), "|"))
+	}
+	if len(route.Auth.States) > 0 {
+		parts = append(parts, "states="+strings.Join(toStrings(route.Auth.States), "|"))
+	}
+	if len(route.Auth.Claims) > 0 {
+		parts = append(parts, "claims="+strings.Join(route.Auth.Claims, "|"))
+	}
+	if route.Auth.Custom > 0 {
+		parts = append(parts, fmt.Sprintf("custom=%d", route.Auth.Custom))
+	}
+	if len(parts) == 0 {
+		return "token"
+	}
+	return strings.Join(parts, " ")
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/auth"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/ctx"
+)
+
+func noop(w http.ResponseWriter, r *http.Request) error { return nil }
+
+func logRequests(next HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error { return next(w, r) }
+}
+
+func TestUnauthenticatedWriteRoutes(t *testing.T) {
+	builder := auth.NewAuthMiddleware("secret").NewBuilder().WithRol(ctx.ContextRolArtist)
+
+	rt := NewRouter(nil)
+	rt.Post("/guard", noop, WithGuard(builder))
+	rt.Post("/build", builder.Build(noop))
+	rt.Post("/marked-build", builder.Build(noop), WithWrapper(logRequests), Authenticated())
+	rt.Post("/build-wrapper", noop, WithWrapper(builder.Build), Authenticated())
+	rt.Post("/marked", noop, Authenticated())
+	rt.Post("/public", noop)
+	rt.Get("/read", noop)
+	rt.Route("/admin", func(r Router) {
+		r.MarkAuthenticated()
+		r.Delete("/songs", noop)
+	})
+	rt.Group(func(r *Router) {
+		r.Put("/group", noop)
+	})
+
+	var got []string
+	for _, route := range rt.UnauthenticatedWriteRoutes() {
+		got = append(got, route.Method+" "+route.Pattern)
+	}
+	// The router cannot see into handlers, so an unmarked auth.Builder.Build route is reported.
+	want := []string{"POST /build", "POST /public", "PUT /group"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("unauthenticated = %v, want %v", got, want)
+	}
+
+	var table strings.Builder
+	if err := rt.PrintRoutes(&table); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "3 write route(s) without authentication") {
+		t.Fatalf("route table:\n%s", table.String())
+	}
+	if !strings.Contains(table.String(), "roles=artist") {
+		t.Fatalf("guard requirements missing from the route table:\n%s", table.String())
+	}
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/openapi"
+)
+
+type OpenAPIConfig struct {
+	Info    openapi.Info
+	Servers []openapi.Server
+	// Path defaults to /openapi.json. The YAML document is served next to it with the .yaml extension.
+	Path string
+	// SwaggerUIPath serves a Swagger UI page when it is not empty, e.g. /docs. The page replaces the
+	// Content-Security-Policy set by the security headers middleware with one that allows its assets.
+	SwaggerUIPath string
+	// SwaggerUIAssetsURL defaults to openapi.DefaultSwaggerUIAssetsURL.
+	SwaggerUIAssetsURL string
+}
+
+// OpenAPI builds the document from every route registered so far.
+func (rt *Router) OpenAPI(info openapi.Info, servers ...openapi.Server) *openapi.Document {
+	generator := openapi.NewGenerator(info, servers...)
+	for _, route := range rt.routes.list() {
+		if route.Hidden {
+			continue
+		}
+		op := openapi.Route{
+			Method:      route.Method,
+			Pattern:     route.Pattern,
+			Summary:     route.Summary,
+			Description: route.Description,
+			Tags:        route.Tags,
+			Request:     route.Request,
+			Responses:   route.Responses,
+		}
+		if route.Auth != nil {
+			op.Auth = &openapi.Auth{
+				Roles:  toStrings(route.Auth.Roles),
+				States: toStrings(route.Auth.States),
+			}
+		}
+		generator.Add(op)
+	}
+	return generator.Document()
+}
+
+// ServeOpenAPI registers the document endpoints. The document is built on each request so it
+// includes routes registered after this call.
+func (rt *Router) ServeOpenAPI(cfg OpenAPIConfig) {
+	if cfg.Path == "" {
+		cfg.Path = "/openapi.json"
+	}
+	yamlPath := strings.TrimSuffix(cfg.Path, ".json") + ".yaml"
+
+	rt.Get(cfg.Path, func(w http.ResponseWriter, r *http.Request) error {
+		data, err := rt.OpenAPI(cfg.Info, cfg.Servers...).JSON()
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, err = w.Write(data)
+		return err
+	}, Hidden())
+
+	rt.Get(yamlPath, func(w http.ResponseWriter, r *http.Request) error {
+		data, err := rt.OpenAPI(cfg.Info, cfg.Servers...).YAML()
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		_, err = w.Write(data)
+		return err
+	}, Hidden())
+
+	if cfg.SwaggerUIPath != "" {
+		specURL := joinPattern(rt.prefix, cfg.Path)
+		rt.Get(cfg.SwaggerUIPath, func(w http.ResponseWriter, r *http.Request) error {
+			page := openapi.SwaggerUI{
+				Title:     cfg.Info.Title,
+				SpecURL:   specURL,
+				AssetsURL: cfg.SwaggerUIAssetsURL,
+				Nonce:     newNonce(),
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Security-Policy", page.ContentSecurityPolicy())
+			_, err := w.Write([]byte(page.HTML()))
+			return err
+		}, Hidden())
+	}
+}
+
+func newNonce() string {
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	return base64.StdEncoding.EncodeToString(nonce)
+}
+
+func toStrings[T ~string](values []T) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = string(v)
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/auth"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/ctx"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/middleware"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/openapi"
+)
+
+func TestSwaggerUIContentSecurityPolicy(t *testing.T) {
+	rt := NewRouter(nil)
+	rt.Use(middleware.SecurityHeaders(middleware.SecurityHeadersConfig{ContentSecurityPolicy: "default-src 'none'"}))
+	rt.ServeOpenAPI(OpenAPIConfig{Info: openapi.Info{Title: "Songs", Version: "1"}, SwaggerUIPath: "/docs"})
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	csp := w.Header().Get("Content-Security-Policy")
+	nonce := regexp.MustCompile(`<script nonce="([^"]+)">`).FindStringSubmatch(w.Body.String())
+	if nonce == nil {
+		t.Fatalf("inline script without nonce:\n%s", w.Body.String())
+	}
+	for _, want := range []string{"'nonce-" + nonce[1] + "'", "script-src https://unpkg.com", "connect-src 'self'"} {
+		if !strings.Contains(csp, want) {
+			t.Errorf("Content-Security-Policy %q does not contain %q", csp, want)
+		}
+	}
+
+	// The API routes keep the policy of the middleware.
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if got := w.Header().Get("Content-Security-Policy"); got != "default-src 'none'" {
+		t.Fatalf("openapi.json Content-Security-Policy = %q", got)
+	}
+}
+
+func TestSwaggerUISelfHostedAssets(t *testing.T) {
+	page := openapi.SwaggerUI{Title: "Songs", SpecURL: "/openapi.json", AssetsURL: "/static/swagger/", Nonce: "abc"}
+	if !strings.Contains(page.HTML(), `href="/static/swagger/swagger-ui.css"`) {
+		t.Fatalf("assets not loaded from AssetsURL:\n%s", page.HTML())
+	}
+	if csp := page.ContentSecurityPolicy(); !strings.Contains(csp, "script-src 'self' 'nonce-abc'") {
+		t.Fatalf("Content-Security-Policy = %q", csp)
+	}
+}
+
+// legacyBuilder implements BuilderInterface without auth.RequirementsProvider, like mocks written
+// before it existed.
+type legacyBuilder struct{}
+
+func (b legacyBuilder) WithState(allowed ...ctx.ContextState) auth.BuilderInterface { return b }
+func (b legacyBuilder) WithRol(allowed ...ctx.ContextRol) auth.BuilderInterface     { return b }
+func (b legacyBuilder) WithClaim(claimKey string, predicate func(value any) bool, errMsg string) auth.BuilderInterface {
+	return b
+}
+func (b legacyBuilder) WithCustom(fn func(r *http.Request) error) auth.BuilderInterface { return b }
+func (b legacyBuilder) Build(next HandlerFunc) HandlerFunc                              { return next }
+
+func TestGuardWithoutRequirements(t *testing.T) {
+	rt := NewRouter(nil)
+	rt.Post("/songs", func(w http.ResponseWriter, r *http.Request) error { return nil }, WithGuard(legacyBuilder{}))
+	rt.Post("/albums", func(w http.ResponseWriter, r *http.Request) error { return nil },
+		WithGuard(auth.NewAuthMiddleware("secret").NewBuilder().WithRol(ctx.ContextRolArtist)))
+
+	routes := rt.Routes()
+	if routes[0].Auth == nil {
+		t.Fatal("guarded route without requirements has no auth")
+	}
+	if routes[1].Auth == nil || len(routes[1].Auth.Roles) != 1 {
+		t.Fatalf("auth = %+v", routes[1].Auth)
+	}
+	if len(rt.UnauthenticatedWriteRoutes()) != 0 {
+		t.Fatalf("unauthenticated = %+v", rt.UnauthenticatedWriteRoutes())
+	}
+}
@@ -1,7 +1,14 @@
 package router
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"runtime/debug"
+	"slices"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/logger"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -9,6 +16,11 @@ import (
 type Router struct {
 	router       *chi.Mux
 	errorHandler func(w http.ResponseWriter, r *http.Request, err error)
+	prefix       string
+	routes       *routeRegistry
+	middlewares  []string
+	// authenticated is set by MarkAuthenticated and inherited by sub routers.
+	authenticated bool
 }
 
 type RouterConfig struct {
@@ -20,6 +32,7 @@ type RouterConfig struct {
 func NewRouter(cfg *RouterConfig) *Router {
 	rt := &Router{
 		router: chi.NewRouter(),
+		routes: &routeRegistry{},
 	}
 
 	if cfg != nil {
@@ -48,11 +61,21 @@ func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (rt *Router) Use(middlewares ...func(http.Handler) http.Handler) {
+	for _, middleware := range middlewares {
+		rt.middlewares = append(rt.middlewares, funcName(middleware))
+	}
 	rt.router.Use(middlewares...)
 }
 
+// MarkAuthenticated records that the routes registered from now on in this router and its sub routers
+// require authentication, for auth applied with Use or UseErrorMiddleware.
+func (rt *Router) MarkAuthenticated() {
+	rt.authenticated = true
+}
+
 func (rt *Router) UseErrorMiddleware(middlewares ...func(w http.ResponseWriter, r *http.Request) error) {
 	for _, middleware := range middlewares {
+		rt.middlewares = append(rt.middlewares, funcName(middleware))
 		rt.router.Use(rt.executeMiddleware(middleware))
 	}
 }
@@ -60,8 +83,12 @@ func (rt *Router) UseErrorMiddleware(middlewares ...func(w http.ResponseWriter,
 func (rt *Router) Route(path string, fn func(r Router)) {
 	rt.router.Route(path, func(r chi.Router) {
 		subRouter := &Router{
-			router:       r.(*chi.Mux),
-			errorHandler: rt.errorHandler,
+			router:        r.(*chi.Mux),
+			errorHandler:  rt.errorHandler,
+			prefix:        joinPattern(rt.prefix, path),
+			routes:        rt.routes,
+			middlewares:   slices.Clone(rt.middlewares),
+			authenticated: rt.authenticated,
 		}
 		fn(*subRouter)
 	})
@@ -69,7 +96,7 @@ func (rt *Router) Route(path string, fn func(r Router)) {
 
 func (rt *Router) executeHandler(handler func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := handler(w, r); err != nil {
+		if err := rt.call(handler, w, r); err != nil {
 			rt.errorHandler(w, r, err)
 		}
 	}
@@ -78,7 +105,7 @@ func (rt *Router) executeHandler(handler func(w http.ResponseWriter, r *http.Req
 func (rt *Router) executeMiddleware(middleware func(w http.ResponseWriter, r *http.Request) error) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if err := middleware(w, r); err != nil {
+			if err := rt.call(middleware, w, r); err != nil {
 				rt.errorHandler(w, r, err)
 				return
 			}
@@ -87,34 +114,55 @@ func (rt *Router) executeMiddleware(middleware func(w http.ResponseWriter, r *ht
 	}
 }
 
-func (rt *Router) Get(path string, handler func(w http.ResponseWriter, r *http.Request) error) {
-	rt.router.Get(path, rt.executeHandler(handler))
+// call converts a panic into an internal AppError so it goes through the ErrorHandler and the
+// server keeps running. http.ErrAbortHandler is re-panicked to let net/http abort the response.
+func (rt *Router) call(fn func(w http.ResponseWriter, r *http.Request) error, w http.ResponseWriter, r *http.Request) (err error) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+		if recErr, ok := rec.(error); ok && errors.Is(recErr, http.ErrAbortHandler) {
+			panic(rec)
+		}
+		logger.Add(r.Context(), logger.Error, logger.LayerHandler, fmt.Sprintf("panic: %v", rec), string(debug.Stack()))
+		err = pkgErrors.NewInternalServerError("An unexpected error occurred")
+	}()
+	return fn(w, r)
+}
+
+func (rt *Router) Get(path string, handler func(w http.ResponseWriter, r *http.Request) error, opts ...RouteOption) {
+	rt.handle(http.MethodGet, path, handler, opts)
 }
 
 func (rt *Router) Group(fn func(r *Router)) {
 	rt.router.Group(func(r chi.Router) {
 		subRouter := &Router{
-			router:       r.(*chi.Mux),
-			errorHandler: rt.errorHandler,
+			router:        r.(*chi.Mux),
+			errorHandler:  rt.errorHandler,
+			prefix:        rt.prefix,
+			routes:        rt.routes,
+			middlewares:   slices.Clone(rt.middlewares),
+			authenticated: rt.authenticated,
 		}
 		fn(subRouter)
 	})
 }
 
-func (rt *Router) Post(path string, handler func(w http.ResponseWriter, r *http.Request) error) {
-	rt.router.Post(path, rt.executeHandler(handler))
+func (rt *Router) Post(path string, handler func(w http.ResponseWriter, r *http.Request) error, opts ...RouteOption) {
+	rt.handle(http.MethodPost, path, handler, opts)
 }
 
-func (rt *Router) Put(path string, handler func(w http.ResponseWriter, r *http.Request) error) {
-	rt.router.Put(path, rt.executeHandler(handler))
+func (rt *Router) Put(path string, handler func(w http.ResponseWriter, r *http.Request) error, opts ...RouteOption) {
+	rt.handle(http.MethodPut, path, handler, opts)
 }
 
-func (rt *Router) Delete(path string, handler func(w http.ResponseWriter, r *http.Request) error) {
-	rt.router.Delete(path, rt.executeHandler(handler))
+func (rt *Router) Delete(path string, handler func(w http.ResponseWriter, r *http.Request) error, opts ...RouteOption) {
+	rt.handle(http.MethodDelete, path, handler, opts)
 }
 
-func (rt *Router) Patch(path string, handler func(w http.ResponseWriter, r *http.Request) error) {
-	rt.router.Patch(path, rt.executeHandler(handler))
+func (rt *Router) Patch(path string, handler func(w http.ResponseWriter, r *http.Request) error, opts ...RouteOption) {
+	rt.handle(http.MethodPatch, path, handler, opts)
 }
 
 type CanRegister interface {
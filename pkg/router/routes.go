@@ -0,0 +1,215 @@
+package router
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/auth"
+)
+
+type HandlerFunc = func(w http.ResponseWriter, r *http.Request) error
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Method      string
+	Pattern     string
+	Summary     string
+	Description string
+	Tags        []string
+	Request     reflect.Type
+	Responses   map[int]reflect.Type
+	// Middlewares lists the router middlewares, the guard and the route wrappers, outermost first.
+	Middlewares []string
+	// Auth is nil when the route is not known to require authentication. It describes the checks of
+	// WithGuard, and is empty for routes marked with Authenticated or Router.MarkAuthenticated.
+	Auth   *auth.Requirements
+	Hidden bool
+}
+
+type routeRegistry struct {
+	mutex  sync.RWMutex
+	routes []RouteInfo
+}
+
+func (reg *routeRegistry) add(info RouteInfo) {
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+	reg.routes = append(reg.routes, info)
+}
+
+func (reg *routeRegistry) list() []RouteInfo {
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+	return append([]RouteInfo(nil), reg.routes...)
+}
+
+type routeConfig struct {
+	status      int
+	wrappers    []func(next HandlerFunc) HandlerFunc
+	guard       auth.BuilderInterface
+	summary     string
+	description string
+	tags        []string
+	request     reflect.Type
+	responses   map[int]reflect.Type
+	hidden      bool
+	// authenticated marks routes guarded by something the router cannot see.
+	authenticated bool
+}
+
+type RouteOption func(cfg *routeConfig)
+
+// WithStatus sets the status code used by typed handlers for successful responses. Defaults to 200.
+func WithStatus(status int) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.status = status
+	}
+}
+
+// WithWrapper wraps the handler. The first wrapper is the outermost.
+func WithWrapper(wrappers ...func(next HandlerFunc) HandlerFunc) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.wrappers = append(cfg.wrappers, wrappers...)
+	}
+}
+
+// WithGuard wraps the handler with the auth builder and records its requirements.
+func WithGuard(guard auth.BuilderInterface) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.guard = guard
+	}
+}
+
+func WithSummary(summary string) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.summary = summary
+	}
+}
+
+func WithDescription(description string) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.description = description
+	}
+}
+
+func WithTags(tags ...string) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.tags = append(cfg.tags, tags...)
+	}
+}
+
+// WithRequest documents the request type, e.g. WithRequest(CreateSongRequest{}).
+func WithRequest(request any) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.request = reflect.TypeOf(request)
+	}
+}
+
+// WithResponse documents a response. A nil body documents an empty response.
+func WithResponse(status int, body any) RouteOption {
+	return func(cfg *routeConfig) {
+		if cfg.responses == nil {
+			cfg.responses = map[int]reflect.Type{}
+		}
+		cfg.responses[status] = reflect.TypeOf(body)
+	}
+}
+
+// Authenticated marks a route that requires a token through means the router cannot see, such as a
+// handler from auth.Builder.Build or an auth middleware mounted on the server, so it is not reported
+// by UnauthenticatedWriteRoutes. Prefer WithGuard, which also records the checks.
+func Authenticated() RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.authenticated = true
+	}
+}
+
+// Hidden leaves the route out of the OpenAPI document.
+func Hidden() RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.hidden = true
+	}
+}
+
+func newRouteConfig(opts []RouteOption) *routeConfig {
+	cfg := &routeConfig{status: http.StatusOK}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+func (rt *Router) handle(method string, path string, handler HandlerFunc, opts []RouteOption) {
+	rt.register(method, path, handler, newRouteConfig(opts))
+}
+
+func (rt *Router) register(method string, path string, handler HandlerFunc, cfg *routeConfig) {
+	for i := len(cfg.wrappers) - 1; i >= 0; i-- {
+		handler = cfg.wrappers[i](handler)
+	}
+
+	middlewares := slices.Clone(rt.middlewares)
+	if cfg.guard != nil {
+		middlewares = append(middlewares, "auth.Builder")
+	}
+	for _, wrapper := range cfg.wrappers {
+		middlewares = append(middlewares, funcName(wrapper))
+	}
+
+	info := RouteInfo{
+		Method:      method,
+		Pattern:     joinPattern(rt.prefix, path),
+		Summary:     cfg.summary,
+		Description: cfg.description,
+		Tags:        cfg.tags,
+		Request:     cfg.request,
+		Responses:   cfg.responses,
+		Middlewares: middlewares,
+		Hidden:      cfg.hidden,
+	}
+
+	if cfg.guard != nil {
+		handler = cfg.guard.Build(handler)
+		var requirements auth.Requirements
+		if provider, ok := cfg.guard.(auth.RequirementsProvider); ok {
+			requirements = provider.Requirements()
+		}
+		info.Auth = &requirements
+	} else if rt.authenticated || cfg.authenticated {
+		info.Auth = &auth.Requirements{}
+	}
+
+	rt.routes.add(info)
+	rt.router.Method(method, path, rt.executeHandler(handler))
+}
+
+func joinPattern(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" || path == "/" {
+		return prefix + path
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+(\.\d+)*)+$`)
+
+// funcName returns a short name such as "logger.RequestLogger" or "(*Limiter).Middleware".
+func funcName(fn any) string {
+	fnc := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if fnc == nil {
+		return "unknown"
+	}
+	name := fnc.Name()
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	return closureSuffix.ReplaceAllString(name, "")
+}
@@ -0,0 +1,149 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+type MessageFunc func(field pkgErrors.FieldError) string
+
+var (
+	messagesMutex sync.RWMutex
+	messages      = map[string]MessageFunc{}
+)
+
+// RegisterMessage sets the message used for a validator tag, overriding the default one.
+func RegisterMessage(tag string, fn MessageFunc) {
+	messagesMutex.Lock()
+	defer messagesMutex.Unlock()
+	messages[tag] = fn
+}
+
+func message(field pkgErrors.FieldError, kind reflect.Kind) string {
+	messagesMutex.RLock()
+	fn, ok := messages[field.Rule]
+	messagesMutex.RUnlock()
+	if ok {
+		return fn(field)
+	}
+	return defaultMessage(field, kind)
+}
+
+func defaultMessage(fe pkgErrors.FieldError, kind reflect.Kind) string {
+	field, param := fe.Field, fe.Param
+
+	switch fe.Rule {
+	case "required", "required_with", "required_with_all", "required_without", "required_without_all":
+		return fmt.Sprintf("%s is required", field)
+	case "isdefault":
+		return fmt.Sprintf("%s must not be set", field)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s", field, withUnit(param, kind))
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s", field, withUnit(param, kind))
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, withUnit(param, kind))
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, withUnit(param, kind))
+	case "len":
+		return fmt.Sprintf("%s must be exactly %s", field, withUnit(param, kind))
+	case "eq":
+		return fmt.Sprintf("%s must be equal to %s", field, param)
+	case "ne":
+		return fmt.Sprintf("%s must not be equal to %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", field, strings.Join(strings.Fields(param), ", "))
+	case "eqfield":
+		return fmt.Sprintf("%s must be equal to %s", field, param)
+	case "nefield":
+		return fmt.Sprintf("%s must not be equal to %s", field, param)
+	case "gtfield", "gtefield", "ltfield", "ltefield":
+		return fmt.Sprintf("%s must be %s %s", field, comparisonWords[strings.TrimSuffix(fe.Rule, "field")], param)
+	case "unique":
+		return fmt.Sprintf("%s must contain unique values", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "url", "uri", "http_url":
+		return fmt.Sprintf("%s must be a valid URL", field)
+	case "uuid", "uuid3", "uuid4", "uuid5":
+		return fmt.Sprintf("%s must be a valid UUID", field)
+	case "alpha":
+		return fmt.Sprintf("%s must contain only letters", field)
+	case "alphanum":
+		return fmt.Sprintf("%s must contain only letters and numbers", field)
+	case "numeric", "number":
+		return fmt.Sprintf("%s must be a number", field)
+	case "hexadecimal":
+		return fmt.Sprintf("%s must be a hexadecimal value", field)
+	case "hexcolor", "rgb", "rgba", "hsl", "hsla":
+		return fmt.Sprintf("%s must be a valid color", field)
+	case "boolean":
+		return fmt.Sprintf("%s must be a boolean", field)
+	case "lowercase":
+		return fmt.Sprintf("%s must be lowercase", field)
+	case "uppercase":
+		return fmt.Sprintf("%s must be uppercase", field)
+	case "contains", "containsany":
+		return fmt.Sprintf("%s must contain %s", field, param)
+	case "excludes", "excludesall":
+		return fmt.Sprintf("%s must not contain %s", field, param)
+	case "startswith":
+		return fmt.Sprintf("%s must start with %s", field, param)
+	case "endswith":
+		return fmt.Sprintf("%s must end with %s", field, param)
+	case "e164":
+		return fmt.Sprintf("%s must be a phone number in E.164 format", field)
+	case "datetime":
+		return fmt.Sprintf("%s must be a date matching %s", field, param)
+	case "ip", "ipv4", "ipv6":
+		return fmt.Sprintf("%s must be a valid IP address", field)
+	case "cidr", "cidrv4", "cidrv6":
+		return fmt.Sprintf("%s must be a valid CIDR", field)
+	case "latitude":
+		return fmt.Sprintf("%s must be a valid latitude", field)
+	case "longitude":
+		return fmt.Sprintf("%s must be a valid longitude", field)
+	case "json":
+		return fmt.Sprintf("%s must be valid JSON", field)
+	case "base64", "base64url":
+		return fmt.Sprintf("%s must be base64 encoded", field)
+	case "iso3166_1_alpha2", "iso3166_1_alpha3":
+		return fmt.Sprintf("%s must be a valid country code", field)
+	case "region":
+		return fmt.Sprintf("%s must be a valid region", field)
+	case "rol":
+		return fmt.Sprintf("%s must be a valid rol", field)
+	case "state":
+		return fmt.Sprintf("%s must be a valid state", field)
+	case "isrc":
+		return fmt.Sprintf("%s must be a valid ISRC", field)
+	case "iso_duration":
+		return fmt.Sprintf("%s must be an ISO 8601 duration", field)
+	case "audio_mime":
+		return fmt.Sprintf("%s must be an audio MIME type", field)
+	case "ascii", "printascii":
+		return fmt.Sprintf("%s must contain only ASCII characters", field)
+	}
+	return fmt.Sprintf("%s is invalid", field)
+}
+
+var comparisonWords = map[string]string{
+	"gt":  "greater than",
+	"gte": "greater than or equal to",
+	"lt":  "less than",
+	"lte": "less than or equal to",
+}
+
+func withUnit(param string, kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return param + " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return param + " items"
+	}
+	return param
+}
@@ -0,0 +1,142 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate is shared so its struct cache survives between requests.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(ParamName)
+	for tag, fn := range defaultValidators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			panic(err)
+		}
+	}
+	return v
+}
+
+// Register adds a validator for tag, replacing any existing one, and optionally its error message.
+// It is not safe to call while requests are being validated, so register validators at startup.
+func Register(tag string, fn validator.Func, msg MessageFunc) error {
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	if msg != nil {
+		RegisterMessage(tag, msg)
+	}
+	return nil
+}
+
+// Validator returns the shared validator instance.
+func Validator() *validator.Validate {
+	return validate
+}
+
+// Struct validates value with its validate tags and returns a validation AppError with one FieldError per failure.
+func Struct(value any) error {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if err := validate.Struct(value); err != nil {
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return pkgErrors.NewValidationError(err.Error())
+		}
+		return pkgErrors.NewFieldValidationError(FieldErrors(v.Type(), errs))
+	}
+	return nil
+}
+
+func FieldErrors(root reflect.Type, errs validator.ValidationErrors) []pkgErrors.FieldError {
+	fields := make([]pkgErrors.FieldError, 0, len(errs))
+	for _, e := range errs {
+		field := pkgErrors.FieldError{
+			Field: fieldPath(root, e.StructNamespace()),
+			Rule:  e.Tag(),
+			Param: e.Param(),
+		}
+		field.Message = message(field, e.Kind())
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// ParamName returns the name a client uses for a field: its json, query, form, path or header tag, or the Go name.
+// A json "-" tag is skipped, so fields kept out of the body such as `json:"-" query:"page"` use their other tag.
+func ParamName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "form", "path", "header"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// fieldPath turns a struct namespace such as "Album.Tracks[0].Title" into "tracks[0].title",
+// dropping the root type and embedded structs the same way encoding/json does.
+func fieldPath(root reflect.Type, structNamespace string) string {
+	segments := strings.Split(structNamespace, ".")
+	if len(segments) > 0 {
+		segments = segments[1:]
+	}
+
+	current := root
+	path := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		for current != nil && current.Kind() == reflect.Ptr {
+			current = current.Elem()
+		}
+
+		var field reflect.StructField
+		found := false
+		if current != nil && current.Kind() == reflect.Struct {
+			field, found = current.FieldByName(name)
+		}
+		if !found {
+			path = append(path, segment)
+			current = nil
+			continue
+		}
+
+		current = field.Type
+		if index != "" {
+			for current.Kind() == reflect.Ptr {
+				current = current.Elem()
+			}
+			for range strings.Count(index, "[") {
+				if k := current.Kind(); k == reflect.Slice || k == reflect.Array || k == reflect.Map {
+					current = current.Elem()
+				}
+			}
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" && index == "" {
+			continue
+		}
+		path = append(path, ParamName(field)+index)
+	}
+
+	return strings.Join(path, ".")
+}
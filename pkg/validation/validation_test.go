@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	pkgErrors "github.com/Melodia-IS2/melodia-go-utils/pkg/errors"
+)
+
+func TestParamName(t *testing.T) {
+	type request struct {
+		Title  string `json:"title,omitempty"`
+		Page   int    `json:"-" query:"page"`
+		Cover  string `form:"cover"`
+		ID     string `path:"id"`
+		Tenant string `header:"X-Tenant"`
+		Hidden string `json:"-"`
+		Plain  string
+	}
+
+	tests := map[string]string{
+		"Title":  "title",
+		"Page":   "page",
+		"Cover":  "cover",
+		"ID":     "id",
+		"Tenant": "X-Tenant",
+		"Hidden": "Hidden",
+		"Plain":  "Plain",
+	}
+
+	typ := reflect.TypeFor[request]()
+	for fieldName, want := range tests {
+		field, _ := typ.FieldByName(fieldName)
+		if got := ParamName(field); got != want {
+			t.Errorf("ParamName(%s) = %q, want %q", fieldName, got, want)
+		}
+	}
+}
+
+func TestStructFieldErrors(t *testing.T) {
+	type track struct {
+		Title string `json:"title" validate:"required"`
+	}
+	type request struct {
+		Page   int     `json:"-" query:"page" validate:"min=1"`
+		Tracks []track `json:"tracks" validate:"dive"`
+	}
+
+	err := Struct(request{Tracks: []track{{Title: "a"}, {}}})
+
+	var appErr *pkgErrors.AppError
+	if !errors.As(err, &appErr) {
+		t.Fatalf("err = %v", err)
+	}
+	var fields []string
+	for _, field := range appErr.Fields {
+		fields = append(fields, field.Field+":"+field.Rule)
+	}
+	want := []string{"page:min", "tracks[1].title:required"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+}
+
+// required on a struct field keeps its validator v9 meaning: the struct is not checked, its fields are.
+func TestRequiredStructField(t *testing.T) {
+	type metadata struct {
+		Genre string `json:"genre"`
+	}
+	type request struct {
+		Metadata metadata `json:"metadata" validate:"required"`
+	}
+
+	if err := Struct(request{}); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+}
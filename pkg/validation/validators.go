@@ -0,0 +1,108 @@
+package validation
+
+import (
+	"mime"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/ctx"
+	"github.com/Melodia-IS2/melodia-go-utils/pkg/region"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var (
+	uuidType = reflect.TypeOf(uuid.UUID{})
+
+	// CC-XXX-YY-NNNNN, hyphens optional.
+	isrcRegex = regexp.MustCompile(`^[A-Z]{2}-?[A-Z0-9]{3}-?[0-9]{2}-?[0-9]{5}$`)
+
+	isoDurationRegex = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+	audioMimeTypes = map[string]bool{
+		"audio/aac":    true,
+		"audio/flac":   true,
+		"audio/x-flac": true,
+		"audio/mp4":    true,
+		"audio/x-m4a":  true,
+		"audio/mpeg":   true,
+		"audio/mp3":    true,
+		"audio/ogg":    true,
+		"audio/opus":   true,
+		"audio/wav":    true,
+		"audio/wave":   true,
+		"audio/x-wav":  true,
+		"audio/webm":   true,
+	}
+)
+
+var defaultValidators = map[string]validator.Func{
+	"region":       isRegion,
+	"rol":          isRol,
+	"state":        isState,
+	"uuid":         isUUID,
+	"isrc":         isISRC,
+	"iso_duration": isISODuration,
+	"audio_mime":   isAudioMime,
+}
+
+func isRegion(fl validator.FieldLevel) bool {
+	value, ok := stringValue(fl)
+	return ok && region.Region(value).IsValid()
+}
+
+func isRol(fl validator.FieldLevel) bool {
+	value, ok := stringValue(fl)
+	return ok && ctx.ContextRol(value).IsValid()
+}
+
+func isState(fl validator.FieldLevel) bool {
+	value, ok := stringValue(fl)
+	return ok && ctx.ContextState(value).IsValid()
+}
+
+// isUUID accepts uuid strings in any case and non nil uuid.UUID values.
+func isUUID(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Type() == uuidType {
+		return field.Interface().(uuid.UUID) != uuid.Nil
+	}
+	value, ok := stringValue(fl)
+	if !ok {
+		return false
+	}
+	_, err := uuid.Parse(value)
+	return err == nil && len(value) == 36
+}
+
+func isISRC(fl validator.FieldLevel) bool {
+	value, ok := stringValue(fl)
+	return ok && isrcRegex.MatchString(value)
+}
+
+func isISODuration(fl validator.FieldLevel) bool {
+	value, ok := stringValue(fl)
+	if !ok || value == "P" || strings.HasSuffix(value, "T") {
+		return false
+	}
+	return isoDurationRegex.MatchString(value)
+}
+
+func isAudioMime(fl validator.FieldLevel) bool {
+	value, ok := stringValue(fl)
+	if !ok {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(value)
+	return err == nil && audioMimeTypes[mediaType]
+}
+
+func stringValue(fl validator.FieldLevel) (string, bool) {
+	field := fl.Field()
+	if field.Kind() != reflect.String {
+		return "", false
+	}
+	return field.String(), true
+}